@@ -0,0 +1,228 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+
+	"github.com/lllllan02/scoreboard/internal/model"
+	"github.com/lllllan02/scoreboard/internal/service"
+)
+
+// boardRenderer 把记分板输出到 w，results 为要输出的队伍，asOf 为截至时刻（0 表示当前时间）
+type boardRenderer func(w io.Writer, scoreboard *service.Scoreboard, results []*model.Result, asOf int64) error
+
+// boardRenderers 各输出格式的记分板渲染器
+var boardRenderers = map[string]boardRenderer{
+	"table":    renderTable,
+	"markdown": renderMarkdown,
+	"jsonl":    renderJSONLines,
+}
+
+// maxCellWidth 表格中队伍名和学校名的最大显示宽度，超出部分截断
+const maxCellWidth = 32
+
+// renderTable 以对齐的表格输出记分板，按终端显示宽度对齐中文
+func renderTable(w io.Writer, scoreboard *service.Scoreboard, results []*model.Result, asOf int64) error {
+	fmt.Fprintln(w, boardTitle(scoreboard, asOf))
+	fmt.Fprintln(w)
+
+	rows := [][]string{boardHeader(scoreboard.Contest)}
+	for _, result := range results {
+		row := boardRow(scoreboard.Contest, result)
+		row[1] = truncate(row[1], maxCellWidth)
+		row[2] = truncate(row[2], maxCellWidth)
+		rows = append(rows, row)
+	}
+
+	widths := make([]int, len(rows[0]))
+	for _, row := range rows {
+		for i, cell := range row {
+			widths[i] = max(widths[i], displayWidth(cell))
+		}
+	}
+
+	for _, row := range rows {
+		var line strings.Builder
+		for i, cell := range row {
+			if i > 0 {
+				line.WriteString("  ")
+			}
+			line.WriteString(cell)
+			if i < len(row)-1 {
+				line.WriteString(strings.Repeat(" ", widths[i]-displayWidth(cell)))
+			}
+		}
+		if _, err := fmt.Fprintln(w, strings.TrimRight(line.String(), " ")); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// renderMarkdown 以 Markdown 表格输出记分板
+func renderMarkdown(w io.Writer, scoreboard *service.Scoreboard, results []*model.Result, asOf int64) error {
+	fmt.Fprintf(w, "## %s\n\n", markdownEscape(boardTitle(scoreboard, asOf)))
+
+	header := boardHeader(scoreboard.Contest)
+	fmt.Fprintf(w, "| %s |\n", strings.Join(header, " | "))
+	fmt.Fprintf(w, "|%s\n", strings.Repeat(" --- |", len(header)))
+
+	for _, result := range results {
+		row := boardRow(scoreboard.Contest, result)
+		for i, cell := range row {
+			row[i] = markdownEscape(cell)
+		}
+		if _, err := fmt.Fprintf(w, "| %s |\n", strings.Join(row, " | ")); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// renderJSONLines 每行输出一个队伍的结果，格式与记分板 API 中的结果相同
+func renderJSONLines(w io.Writer, scoreboard *service.Scoreboard, results []*model.Result, asOf int64) error {
+	encoder := json.NewEncoder(w)
+	for _, result := range results {
+		if err := encoder.Encode(result); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// boardTitle 记分板标题：比赛名称、截至时刻、封榜状态和版本
+func boardTitle(scoreboard *service.Scoreboard, asOf int64) string {
+	contest := scoreboard.Contest
+	at := asOf
+	if at == 0 {
+		at = time.Now().Unix()
+	}
+
+	title := fmt.Sprintf("%s  as of %s", contest.Name, time.Unix(at, 0).Format("2006-01-02 15:04:05"))
+	if contest.IsBoardFrozen(at, time.Now().Unix()) {
+		title += "  (frozen)"
+	}
+	return fmt.Sprintf("%s  version %d", title, scoreboard.Version)
+}
+
+// boardHeader 表头：排名、队伍、学校、解题数、罚时、各题和奖牌
+func boardHeader(contest *model.Contest) []string {
+	header := []string{"Rank", "Team", "Organization", "Score", "Penalty"}
+	header = append(header, contest.ProblemIDs...)
+	return append(header, "Medal")
+}
+
+// boardRow 队伍在记分板中的一行，打星队伍的队名后加 *
+func boardRow(contest *model.Contest, result *model.Result) []string {
+	name := result.Team.Name
+	if !result.Team.IsOfficial() {
+		name += " *"
+	}
+
+	row := []string{
+		strconv.Itoa(result.Rank),
+		name,
+		result.Team.Organization,
+		strconv.Itoa(result.Score),
+		strconv.FormatInt(result.TotalTime, 10),
+	}
+
+	scoreBased := isScoreBased(contest)
+	for _, problemID := range contest.ProblemIDs {
+		row = append(row, problemCell(result.ProblemResults[problemID], scoreBased))
+	}
+	return append(row, result.Medal)
+}
+
+// isScoreBased 判断比赛是否按得分（而非通过题数）排名
+func isScoreBased(contest *model.Contest) bool {
+	switch contest.RuleSet().Name() {
+	case "oi", "codeforces":
+		return true
+	}
+	return false
+}
+
+// problemCell 题目格子：通过为 +错误次数/通过时间（按得分排名时为得分），
+// 未通过为 -错误次数，封榜后的待定提交为 ?次数
+func problemCell(problem *model.ProblemResult, scoreBased bool) string {
+	if problem == nil {
+		return ""
+	}
+
+	var cell string
+	switch {
+	case scoreBased && problem.Score > 0:
+		cell = strconv.Itoa(problem.Score)
+	case problem.Solved:
+		cell = "+"
+		if problem.Attempts > 0 {
+			cell += strconv.Itoa(problem.Attempts)
+		}
+		cell += "/" + strconv.FormatInt(problem.SolvedTime, 10)
+	case problem.Attempts > 0:
+		cell = "-" + strconv.Itoa(problem.Attempts)
+	}
+
+	if problem.PendingAttempts > 0 {
+		cell += "?" + strconv.Itoa(problem.PendingAttempts)
+	}
+	return cell
+}
+
+// markdownEscape 转义 Markdown 表格中的特殊字符
+func markdownEscape(s string) string {
+	return strings.NewReplacer(`\`, `\\`, "|", `\|`, "*", `\*`, "_", `\_`).Replace(s)
+}
+
+// truncate 把字符串截断到 width 个显示宽度，截断时以 … 结尾
+func truncate(s string, width int) string {
+	if displayWidth(s) <= width {
+		return s
+	}
+
+	var b strings.Builder
+	used := 0
+	for _, r := range s {
+		if used+runeWidth(r) > width-1 {
+			break
+		}
+		b.WriteRune(r)
+		used += runeWidth(r)
+	}
+	return b.String() + "…"
+}
+
+// displayWidth 字符串在终端中的显示宽度
+func displayWidth(s string) int {
+	width := 0
+	for _, r := range s {
+		width += runeWidth(r)
+	}
+	return width
+}
+
+// runeWidth 字符在终端中的显示宽度：组合字符为 0，中日韩文字和全角字符为 2
+func runeWidth(r rune) int {
+	switch {
+	case unicode.Is(unicode.Mn, r) || unicode.Is(unicode.Me, r) || r == '\u200b':
+		return 0
+	case r >= 0x1100 && r <= 0x115f, // 谚文字母
+		r >= 0x2e80 && r <= 0xa4cf && r != 0x303f, // 中日韩部首、符号、汉字、彝文
+		r >= 0xac00 && r <= 0xd7a3,                // 谚文音节
+		r >= 0xf900 && r <= 0xfaff,                // 中日韩兼容汉字
+		r >= 0xfe30 && r <= 0xfe4f,                // 中日韩兼容形式
+		r >= 0xff00 && r <= 0xff60,                // 全角字符
+		r >= 0xffe0 && r <= 0xffe6,
+		r >= 0x1f300 && r <= 0x1f64f, // 表情符号
+		r >= 0x1f900 && r <= 0x1f9ff,
+		r >= 0x20000 && r <= 0x3fffd: // 中日韩扩展汉字
+		return 2
+	}
+	return 1
+}
@@ -0,0 +1,206 @@
+package main
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/lllllan02/scoreboard/internal/model"
+	"github.com/lllllan02/scoreboard/internal/model/modeltest"
+	"github.com/lllllan02/scoreboard/internal/service"
+)
+
+// newTestBoard 创建两支队伍的记分板，其中一支为中文队名的打星队伍
+func newTestBoard() *service.Scoreboard {
+	contest := modeltest.NewContest()
+	contest.FrozenTime = 3600
+	return &service.Scoreboard{
+		Contest: contest,
+		Version: 3,
+		Results: []*model.Result{
+			{
+				TeamID: "t1",
+				Team:   &model.Team{ID: "t1", Name: "Alpha", Organization: "School 1"},
+				Rank:   1, Score: 2, TotalTime: 100, Medal: "gold",
+				ProblemResults: map[string]*model.ProblemResult{
+					"A": {Solved: true, SolvedTime: 20},
+					"B": {Solved: true, Attempts: 2, SolvedTime: 40},
+					"C": {Attempts: 1, PendingAttempts: 1},
+				},
+			},
+			{
+				TeamID: "t2",
+				Team:   &model.Team{ID: "t2", Name: "测试队", Organization: "大学", Groups: []string{"unofficial"}},
+				Rank:   2, Score: 0,
+				ProblemResults: map[string]*model.ProblemResult{
+					"A": {Attempts: 3},
+				},
+			},
+		},
+	}
+}
+
+func TestRenderTable(t *testing.T) {
+	board := newTestBoard()
+	var buf bytes.Buffer
+	if err := renderTable(&buf, board, board.Results, board.Contest.StartTime+30*60); err != nil {
+		t.Fatal(err)
+	}
+
+	// 第一行为标题，第二行为空行；各列按显示宽度对齐，中文占两列
+	lines := strings.Split(strings.TrimSuffix(buf.String(), "\n"), "\n")
+	want := []string{
+		"Rank  Team      Organization  Score  Penalty  A     B      C     Medal",
+		"1     Alpha     School 1      2      100      +/20  +2/40  -1?1  gold",
+		"2     测试队 *  大学          0      0        -3",
+	}
+	if len(lines) != 5 || strings.Join(lines[2:], "\n") != strings.Join(want, "\n") {
+		t.Errorf("got:\n%s\nwant:\n%s", buf.String(), strings.Join(want, "\n"))
+	}
+}
+
+func TestRenderMarkdown(t *testing.T) {
+	board := newTestBoard()
+	board.Results[0].Team.Name = "a|b_c"
+	var buf bytes.Buffer
+	if err := renderMarkdown(&buf, board, board.Results[:1], board.Contest.StartTime+30*60); err != nil {
+		t.Fatal(err)
+	}
+
+	lines := strings.Split(buf.String(), "\n")
+	want := []string{
+		"| Rank | Team | Organization | Score | Penalty | A | B | C | Medal |",
+		"| --- | --- | --- | --- | --- | --- | --- | --- | --- |",
+		`| 1 | a\|b\_c | School 1 | 2 | 100 | +/20 | +2/40 | -1?1 | gold |`,
+	}
+	if len(lines) < 5 || strings.Join(lines[2:5], "\n") != strings.Join(want, "\n") {
+		t.Errorf("got:\n%s\nwant:\n%s", strings.Join(lines, "\n"), strings.Join(want, "\n"))
+	}
+}
+
+func TestBoardTitle(t *testing.T) {
+	board := newTestBoard()
+	contest := board.Contest
+
+	tests := []struct {
+		name       string
+		asOf       int64
+		wantFrozen bool
+	}{
+		{"before the freeze", contest.StartTime + 60*60, false},
+		{"during the freeze", contest.EndTime - 30*60, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			title := boardTitle(board, tt.asOf)
+			if got := strings.Contains(title, "(frozen)"); got != tt.wantFrozen {
+				t.Errorf("boardTitle() = %q, want frozen: %v", title, tt.wantFrozen)
+			}
+			if !strings.HasPrefix(title, contest.Name) || !strings.HasSuffix(title, "version 3") {
+				t.Errorf("boardTitle() = %q, want the contest name and version", title)
+			}
+		})
+	}
+}
+
+func TestProblemCell(t *testing.T) {
+	tests := []struct {
+		name       string
+		problem    *model.ProblemResult
+		scoreBased bool
+		want       string
+	}{
+		{"no submissions", nil, false, ""},
+		{"solved first try", &model.ProblemResult{Solved: true, SolvedTime: 12}, false, "+/12"},
+		{"solved after attempts", &model.ProblemResult{Solved: true, Attempts: 2, SolvedTime: 30}, false, "+2/30"},
+		{"unsolved", &model.ProblemResult{Attempts: 3}, false, "-3"},
+		{"pending", &model.ProblemResult{Attempts: 1, PendingAttempts: 2}, false, "-1?2"},
+		{"partial score", &model.ProblemResult{Attempts: 2, Score: 60}, true, "60"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := problemCell(tt.problem, tt.scoreBased); got != tt.want {
+				t.Errorf("problemCell() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestTruncate(t *testing.T) {
+	tests := []struct {
+		s     string
+		width int
+		want  string
+	}{
+		{"Alpha", 8, "Alpha"},
+		{"Alphabetical", 8, "Alphabe…"},
+		{"中文队伍名称", 8, "中文队…"},
+		{"中文队伍", 8, "中文队伍"},
+	}
+
+	for _, tt := range tests {
+		if got := truncate(tt.s, tt.width); got != tt.want || displayWidth(got) > tt.width {
+			t.Errorf("truncate(%q, %d) = %q, want %q", tt.s, tt.width, got, tt.want)
+		}
+	}
+}
+
+func TestTopResults(t *testing.T) {
+	results := []*model.Result{{Rank: 1}, {Rank: 2}, {Rank: 2}, {Rank: 4}}
+
+	tests := []struct {
+		n    int
+		want int
+	}{
+		{0, 4},
+		{1, 1},
+		{2, 3}, // 并列的队伍一起保留
+		{10, 4},
+	}
+
+	for _, tt := range tests {
+		if got := topResults(results, tt.n); len(got) != tt.want {
+			t.Errorf("topResults(%d) kept %d teams, want %d", tt.n, len(got), tt.want)
+		}
+	}
+}
+
+func TestParseAsOf(t *testing.T) {
+	contest := modeltest.NewContest()
+
+	tests := []struct {
+		value   string
+		want    int64
+		wantErr bool
+	}{
+		{"", 0, false},
+		{"1700003600", 1700003600, false},
+		{"2023-11-14T22:13:20Z", 1700000000, false},
+		{"4h", contest.StartTime + 4*3600, false},
+		{"-1h", 0, true},
+		{"soon", 0, true},
+	}
+
+	for _, tt := range tests {
+		got, err := parseAsOf(contest, tt.value)
+		if (err != nil) != tt.wantErr || got != tt.want {
+			t.Errorf("parseAsOf(%q) = %d, %v, want %d", tt.value, got, err, tt.want)
+		}
+	}
+}
+
+func TestCheckFilter(t *testing.T) {
+	contest := modeltest.NewContest()
+	contest.Groups["zju"] = "浙江大学"
+
+	for _, filter := range []string{"", "official", "girls", "zju"} {
+		if err := checkFilter(contest, filter); err != nil {
+			t.Errorf("checkFilter(%q) = %v, want nil", filter, err)
+		}
+	}
+	if err := checkFilter(contest, "zj"); err == nil || !strings.Contains(err.Error(), "zju") {
+		t.Errorf("checkFilter(zj) = %v, want an error listing the valid filters", err)
+	}
+}
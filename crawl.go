@@ -0,0 +1,93 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/lllllan02/scoreboard/internal/model"
+)
+
+// defaultCrawlSource 爬取比赛数据的网站
+const defaultCrawlSource = "https://board.xcpcio.com"
+
+// crawlClient 爬取数据使用的客户端，超时后放弃请求，避免网站无响应时一直等待
+var crawlClient = &http.Client{Timeout: time.Minute}
+
+// runCrawl 爬取比赛的配置、队伍和提交记录，保存到存储并加入比赛目录
+// 参数可以是比赛ID，也可以是比赛页面的网址
+func runCrawl(args []string) error {
+	fs, flags := newFlagSet("crawl", "<contest-id or url>...")
+	source := fs.String("source", defaultCrawlSource, "site to crawl contests from")
+	if err := parseFlags(fs, args); err != nil {
+		return err
+	}
+	if err := requireArgs(fs, 1); err != nil {
+		return err
+	}
+
+	cfg, err := loadConfig(flags)
+	if err != nil {
+		return err
+	}
+	store, closeStore, err := openStore(cfg)
+	if err != nil {
+		return err
+	}
+	defer closeStore()
+
+	base := strings.TrimSuffix(*source, "/")
+	for _, arg := range fs.Args() {
+		contestID := strings.Trim(strings.TrimPrefix(arg, base), "/")
+		if err := crawlContest(store, base, contestID); err != nil {
+			return fmt.Errorf("failed to crawl %s: %w", contestID, err)
+		}
+
+		contest, err := model.LoadContestConfig(store, contestID)
+		if err != nil {
+			return err
+		}
+		if err := model.AddContestToDirectory(store, contest); err != nil {
+			return err
+		}
+		fmt.Printf("crawled %s\n", contestID)
+	}
+	return nil
+}
+
+// crawlContest 爬取比赛的配置、队伍和提交记录并保存到存储
+func crawlContest(store model.ContestStore, base, contestID string) error {
+	config, err := crawl(fmt.Sprintf("%s/data/%s/config.json", base, contestID))
+	if err != nil {
+		return err
+	}
+
+	teams, err := crawl(fmt.Sprintf("%s/data/%s/team.json", base, contestID))
+	if err != nil {
+		return err
+	}
+
+	runs, err := crawl(fmt.Sprintf("%s/data/%s/run.json", base, contestID))
+	if err != nil {
+		return err
+	}
+
+	return store.ImportContest(contestID, config, teams, runs)
+}
+
+// crawl 下载 url 的内容
+func crawl(url string) ([]byte, error) {
+	resp, err := crawlClient.Get(url)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("failed to crawl %s: %s", url, resp.Status)
+	}
+
+	return io.ReadAll(resp.Body)
+}
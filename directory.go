@@ -0,0 +1,36 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/lllllan02/scoreboard/internal/model"
+)
+
+// runRebuildDirectory 重新扫描存储，修复比赛目录中缺失或过时的比赛
+func runRebuildDirectory(args []string) error {
+	fs, flags := newFlagSet("rebuild-directory", "")
+	if err := parseFlags(fs, args); err != nil {
+		return err
+	}
+
+	cfg, err := loadConfig(flags)
+	if err != nil {
+		return err
+	}
+	store, closeStore, err := openStore(cfg)
+	if err != nil {
+		return err
+	}
+	defer closeStore()
+
+	changes, err := model.RebuildContestDirectory(store)
+	if err != nil {
+		return err
+	}
+
+	// 输出目录的变化，便于脚本处理
+	out, _ := json.MarshalIndent(changes, "", "  ")
+	fmt.Println(string(out))
+	return nil
+}
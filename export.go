@@ -0,0 +1,119 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"path/filepath"
+
+	"github.com/lllllan02/scoreboard/internal/model"
+)
+
+// runExport 把存储中的比赛导出为 xcpcio 格式的数据目录，不指定比赛时导出全部比赛
+func runExport(args []string) error {
+	fs, flags := newFlagSet("export", "[contest-id...]")
+	out := fs.String("out", "export", "data directory to export into")
+	if err := parseFlags(fs, args); err != nil {
+		return err
+	}
+
+	cfg, err := loadConfig(flags)
+	if err != nil {
+		return err
+	}
+	store, closeStore, err := openStore(cfg)
+	if err != nil {
+		return err
+	}
+	defer closeStore()
+
+	if cfg.DBPath == "" && samePath(cfg.DataDir, *out) {
+		return fmt.Errorf("export directory is the data directory: %s", *out)
+	}
+
+	dst := model.NewFileStore(*out)
+	if err := copyContests(dst, store, fs.Args(), "exported"); err != nil {
+		return err
+	}
+
+	// 导出目录的比赛目录根据导出的比赛生成
+	_, err = model.RebuildContestDirectory(dst)
+	return err
+}
+
+// runImport 把数据目录中的比赛导入存储，不指定比赛时导入全部比赛和比赛目录
+func runImport(args []string) error {
+	fs, flags := newFlagSet("import", "[contest-id...]")
+	from := fs.String("from", model.DefaultDataRoot, "data directory to import from")
+	if err := parseFlags(fs, args); err != nil {
+		return err
+	}
+
+	cfg, err := loadConfig(flags)
+	if err != nil {
+		return err
+	}
+	if cfg.DBPath == "" && samePath(cfg.DataDir, *from) {
+		return fmt.Errorf("import source is the data directory, set -db or -data to import elsewhere")
+	}
+
+	store, closeStore, err := openStore(cfg)
+	if err != nil {
+		return err
+	}
+	defer closeStore()
+
+	src := model.NewFileStore(*from)
+	if err := copyContests(store, src, fs.Args(), "imported"); err != nil {
+		return err
+	}
+	if fs.NArg() > 0 {
+		return nil
+	}
+
+	// 有目录时直接复制，否则根据导入的比赛生成
+	directory, err := src.LoadDirectory()
+	if errors.Is(err, model.ErrNotExist) {
+		_, err := model.RebuildContestDirectory(store)
+		return err
+	}
+	if err != nil {
+		return err
+	}
+	return model.UpdateContestDirectory(store, directory.Contests)
+}
+
+// copyContests 把 src 中的比赛复制到 dst，contestIDs 为空时复制全部比赛
+// 指定比赛时把复制的比赛加入 dst 的比赛目录
+func copyContests(dst, src model.ContestStore, contestIDs []string, verb string) error {
+	all := len(contestIDs) == 0
+	if all {
+		var err error
+		if contestIDs, err = src.ListContests(); err != nil {
+			return err
+		}
+	}
+
+	for _, contestID := range contestIDs {
+		if err := model.CopyContest(dst, src, contestID); err != nil {
+			return fmt.Errorf("failed to copy %s: %w", contestID, err)
+		}
+		if !all {
+			contest, err := model.LoadContestConfig(dst, contestID)
+			if err != nil {
+				return err
+			}
+			if err := model.AddContestToDirectory(dst, contest); err != nil {
+				return err
+			}
+		}
+		fmt.Printf("%s %s\n", verb, contestID)
+	}
+	return nil
+}
+
+// samePath 判断两个路径是否指向同一位置
+func samePath(a, b string) bool {
+	absA, errA := filepath.Abs(a)
+	absB, errB := filepath.Abs(b)
+	return errA == nil && errB == nil && absA == absB
+}
@@ -0,0 +1,258 @@
+// Package config 加载服务器和命令行工具的配置，优先级从低到高为：默认值、配置文件、环境变量、命令行参数
+package config
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Config 服务器配置
+type Config struct {
+	Addr        string `json:"addr" yaml:"addr"`                 // 监听地址
+	DataDir     string `json:"data_dir" yaml:"data_dir"`         // 比赛数据目录
+	DBPath      string `json:"db_path" yaml:"db_path"`           // SQLite 数据库路径，设置后代替数据目录
+	Dev         bool   `json:"dev" yaml:"dev"`                   // 开发模式：从磁盘加载页面模板和静态文件，修改后立即生效
+	TemplateDir string `json:"template_dir" yaml:"template_dir"` // 开发模式下的页面模板目录
+	StaticDir   string `json:"static_dir" yaml:"static_dir"`     // 开发模式下的静态文件目录
+
+	ReadTimeout     Duration `json:"read_timeout" yaml:"read_timeout"`
+	WriteTimeout    Duration `json:"write_timeout" yaml:"write_timeout"` // 不限制 SSE 等长连接
+	IdleTimeout     Duration `json:"idle_timeout" yaml:"idle_timeout"`
+	ShutdownTimeout Duration `json:"shutdown_timeout" yaml:"shutdown_timeout"` // 优雅退出时等待请求完成的最长时间
+	WatchInterval   Duration `json:"watch_interval" yaml:"watch_interval"`     // 检查比赛数据变化的间隔
+
+	TLSCert string `json:"tls_cert" yaml:"tls_cert"` // 同时设置证书和私钥时启用 HTTPS
+	TLSKey  string `json:"tls_key" yaml:"tls_key"`
+
+	LogLevel   string `json:"log_level" yaml:"log_level"`     // debug、info、warn 或 error
+	AdminToken string `json:"admin_token" yaml:"admin_token"` // 管理接口的令牌，为空时禁用管理接口
+}
+
+// Duration 配置文件中以 "10s"、"1m30s" 形式书写的时长
+type Duration time.Duration
+
+// UnmarshalText 解析时长
+func (d *Duration) UnmarshalText(text []byte) error {
+	parsed, err := time.ParseDuration(string(text))
+	if err != nil {
+		return err
+	}
+	*d = Duration(parsed)
+	return nil
+}
+
+// MarshalText 输出时长
+func (d Duration) MarshalText() ([]byte, error) {
+	return []byte(time.Duration(d).String()), nil
+}
+
+// Default 默认配置
+func Default() *Config {
+	return &Config{
+		Addr:            ":8080",
+		DataDir:         "data",
+		TemplateDir:     "web/templates",
+		StaticDir:       "web/static",
+		ReadTimeout:     Duration(15 * time.Second),
+		WriteTimeout:    Duration(30 * time.Second),
+		IdleTimeout:     Duration(2 * time.Minute),
+		ShutdownTimeout: Duration(10 * time.Second),
+		WatchInterval:   Duration(2 * time.Second),
+		LogLevel:        "info",
+	}
+}
+
+// Flags 注册在命令行参数集上的配置参数，参数集解析完成后通过 Load 得到配置
+type Flags struct {
+	fs         *flag.FlagSet
+	configPath *string
+	values     *Config
+}
+
+// NewFlags 在 fs 上注册所有命令共用的参数：配置文件、数据目录、数据库和日志级别
+// 配置文件由 -config 参数或 SCOREBOARD_CONFIG 环境变量指定，按扩展名解析 JSON 或 YAML
+func NewFlags(fs *flag.FlagSet) *Flags {
+	f := &Flags{fs: fs, values: Default()}
+	f.configPath = fs.String("config", os.Getenv("SCOREBOARD_CONFIG"), "config file (.json, .yaml or .yml)")
+	fs.StringVar(&f.values.DataDir, "data", f.values.DataDir, "contest data directory")
+	fs.StringVar(&f.values.DBPath, "db", f.values.DBPath, "sqlite database, used instead of the data directory")
+	fs.StringVar(&f.values.LogLevel, "log-level", f.values.LogLevel, "log level: debug, info, warn or error")
+	return f
+}
+
+// RegisterServer 注册只有服务器使用的参数
+func (f *Flags) RegisterServer() {
+	fs, flags := f.fs, f.values
+	fs.StringVar(&flags.Addr, "addr", flags.Addr, "listen address")
+	fs.BoolVar(&flags.Dev, "dev", flags.Dev, "load templates and static files from disk and reload templates on every request")
+	fs.StringVar(&flags.TemplateDir, "templates", flags.TemplateDir, "template directory used in dev mode")
+	fs.StringVar(&flags.StaticDir, "static", flags.StaticDir, "static file directory used in dev mode")
+	fs.Var(&flags.ReadTimeout, "read-timeout", "read timeout")
+	fs.Var(&flags.WriteTimeout, "write-timeout", "write timeout, not applied to event streams")
+	fs.Var(&flags.IdleTimeout, "idle-timeout", "keep-alive idle timeout")
+	fs.Var(&flags.ShutdownTimeout, "shutdown-timeout", "how long to wait for requests on shutdown")
+	fs.Var(&flags.WatchInterval, "watch-interval", "how often to check contest data for changes")
+	fs.StringVar(&flags.TLSCert, "tls-cert", flags.TLSCert, "TLS certificate file")
+	fs.StringVar(&flags.TLSKey, "tls-key", flags.TLSKey, "TLS private key file")
+}
+
+// Load 按默认值、配置文件、环境变量、命令行参数的顺序加载配置，需要在参数集解析完成后调用
+func (f *Flags) Load() (*Config, error) {
+	cfg := Default()
+
+	if *f.configPath != "" {
+		if err := cfg.loadFile(*f.configPath); err != nil {
+			return nil, err
+		}
+	}
+
+	cfg.loadEnv()
+
+	// 只覆盖命令行中显式指定的参数
+	flags := f.values
+	f.fs.Visit(func(fl *flag.Flag) {
+		switch fl.Name {
+		case "addr":
+			cfg.Addr = flags.Addr
+		case "data":
+			cfg.DataDir = flags.DataDir
+		case "db":
+			cfg.DBPath = flags.DBPath
+		case "dev":
+			cfg.Dev = flags.Dev
+		case "templates":
+			cfg.TemplateDir = flags.TemplateDir
+		case "static":
+			cfg.StaticDir = flags.StaticDir
+		case "read-timeout":
+			cfg.ReadTimeout = flags.ReadTimeout
+		case "write-timeout":
+			cfg.WriteTimeout = flags.WriteTimeout
+		case "idle-timeout":
+			cfg.IdleTimeout = flags.IdleTimeout
+		case "shutdown-timeout":
+			cfg.ShutdownTimeout = flags.ShutdownTimeout
+		case "watch-interval":
+			cfg.WatchInterval = flags.WatchInterval
+		case "tls-cert":
+			cfg.TLSCert = flags.TLSCert
+		case "tls-key":
+			cfg.TLSKey = flags.TLSKey
+		case "log-level":
+			cfg.LogLevel = flags.LogLevel
+		}
+	})
+
+	if err := cfg.Validate(); err != nil {
+		return nil, err
+	}
+	return cfg, nil
+}
+
+// loadFile 从配置文件加载，文件中没有出现的字段保持原值
+func (c *Config) loadFile(path string) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("failed to read config file: %w", err)
+	}
+
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".json":
+		err = json.Unmarshal(data, c)
+	case ".yaml", ".yml":
+		err = yaml.Unmarshal(data, c)
+	default:
+		return fmt.Errorf("unsupported config file format: %s", path)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to parse config file: %w", err)
+	}
+	return nil
+}
+
+// loadEnv 从环境变量加载，PORT 为兼容旧的启动方式
+func (c *Config) loadEnv() {
+	if port := os.Getenv("PORT"); port != "" {
+		c.Addr = ":" + port
+	}
+
+	stringVars := map[string]*string{
+		"SCOREBOARD_ADDR": &c.Addr,
+		"DATA_DIR":        &c.DataDir,
+		"DB_PATH":         &c.DBPath,
+		"TEMPLATE_DIR":    &c.TemplateDir,
+		"STATIC_DIR":      &c.StaticDir,
+		"TLS_CERT":        &c.TLSCert,
+		"TLS_KEY":         &c.TLSKey,
+		"LOG_LEVEL":       &c.LogLevel,
+		"ADMIN_TOKEN":     &c.AdminToken,
+	}
+	for name, field := range stringVars {
+		if value := os.Getenv(name); value != "" {
+			*field = value
+		}
+	}
+
+	if dev, err := strconv.ParseBool(os.Getenv("SCOREBOARD_DEV")); err == nil {
+		c.Dev = dev
+	}
+
+	durationVars := map[string]*Duration{
+		"READ_TIMEOUT":     &c.ReadTimeout,
+		"WRITE_TIMEOUT":    &c.WriteTimeout,
+		"IDLE_TIMEOUT":     &c.IdleTimeout,
+		"SHUTDOWN_TIMEOUT": &c.ShutdownTimeout,
+		"WATCH_INTERVAL":   &c.WatchInterval,
+	}
+	for name, field := range durationVars {
+		if value := os.Getenv(name); value != "" {
+			if err := field.UnmarshalText([]byte(value)); err != nil {
+				slog.Warn("忽略无效的环境变量", "name", name, "value", value)
+			}
+		}
+	}
+}
+
+// Validate 检查配置是否有效
+func (c *Config) Validate() error {
+	if (c.TLSCert == "") != (c.TLSKey == "") {
+		return fmt.Errorf("tls_cert and tls_key must be set together")
+	}
+	if _, err := c.SlogLevel(); err != nil {
+		return err
+	}
+	return nil
+}
+
+// TLSEnabled 判断是否启用 HTTPS
+func (c *Config) TLSEnabled() bool {
+	return c.TLSCert != "" && c.TLSKey != ""
+}
+
+// SlogLevel 获取日志级别
+func (c *Config) SlogLevel() (slog.Level, error) {
+	var level slog.Level
+	if err := level.UnmarshalText([]byte(c.LogLevel)); err != nil {
+		return 0, fmt.Errorf("invalid log level %q", c.LogLevel)
+	}
+	return level, nil
+}
+
+// String 实现 flag.Value，用于命令行参数
+func (d *Duration) String() string {
+	return time.Duration(*d).String()
+}
+
+// Set 实现 flag.Value，用于命令行参数
+func (d *Duration) Set(value string) error {
+	return d.UnmarshalText([]byte(value))
+}
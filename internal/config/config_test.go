@@ -0,0 +1,146 @@
+package config
+
+import (
+	"flag"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+// load 用服务器的参数集解析 args 并加载配置
+func load(args []string) (*Config, error) {
+	fs := flag.NewFlagSet("serve", flag.ContinueOnError)
+	fs.SetOutput(io.Discard)
+	flags := NewFlags(fs)
+	flags.RegisterServer()
+	if err := fs.Parse(args); err != nil {
+		return nil, err
+	}
+	return flags.Load()
+}
+
+func TestLoadPrecedence(t *testing.T) {
+	dir := t.TempDir()
+	yamlPath := filepath.Join(dir, "config.yaml")
+	if err := os.WriteFile(yamlPath, []byte("addr: \":9000\"\ndata_dir: file-data\nstatic_dir: file-static\nread_timeout: 5s\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	jsonPath := filepath.Join(dir, "config.json")
+	if err := os.WriteFile(jsonPath, []byte(`{"addr": ":9100", "idle_timeout": "1m"}`), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name string
+		env  map[string]string
+		args []string
+		want func(cfg *Config) bool
+	}{
+		{
+			name: "defaults",
+			want: func(cfg *Config) bool {
+				return cfg.Addr == ":8080" && cfg.DataDir == "data" && cfg.ReadTimeout == Duration(15*time.Second)
+			},
+		},
+		{
+			name: "file overrides defaults",
+			args: []string{"-config", yamlPath},
+			want: func(cfg *Config) bool {
+				return cfg.Addr == ":9000" && cfg.DataDir == "file-data" && cfg.ReadTimeout == Duration(5*time.Second) &&
+					cfg.TemplateDir == "web/templates"
+			},
+		},
+		{
+			name: "config path from the environment",
+			env:  map[string]string{"SCOREBOARD_CONFIG": jsonPath},
+			want: func(cfg *Config) bool {
+				return cfg.Addr == ":9100" && cfg.IdleTimeout == Duration(time.Minute)
+			},
+		},
+		{
+			name: "environment overrides file",
+			env:  map[string]string{"PORT": "7000", "DATA_DIR": "env-data", "READ_TIMEOUT": "7s"},
+			args: []string{"-config", yamlPath},
+			want: func(cfg *Config) bool {
+				return cfg.Addr == ":7000" && cfg.DataDir == "env-data" && cfg.StaticDir == "file-static" &&
+					cfg.ReadTimeout == Duration(7*time.Second)
+			},
+		},
+		{
+			name: "flags override environment",
+			env:  map[string]string{"DATA_DIR": "env-data", "READ_TIMEOUT": "7s"},
+			args: []string{"-config", yamlPath, "-data", "flag-data", "-read-timeout", "3s"},
+			want: func(cfg *Config) bool {
+				return cfg.Addr == ":9000" && cfg.DataDir == "flag-data" && cfg.ReadTimeout == Duration(3*time.Second)
+			},
+		},
+		{
+			// 命令行参数与默认值相同时也覆盖其他来源
+			name: "explicit flag with the default value",
+			env:  map[string]string{"SCOREBOARD_ADDR": ":7100"},
+			args: []string{"-addr", ":8080"},
+			want: func(cfg *Config) bool {
+				return cfg.Addr == ":8080"
+			},
+		},
+		{
+			name: "dev mode from the environment",
+			env:  map[string]string{"SCOREBOARD_DEV": "true"},
+			want: func(cfg *Config) bool {
+				return cfg.Dev
+			},
+		},
+		{
+			name: "invalid environment duration is ignored",
+			env:  map[string]string{"WATCH_INTERVAL": "soon"},
+			want: func(cfg *Config) bool {
+				return cfg.WatchInterval == Duration(2*time.Second)
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			for _, name := range []string{"SCOREBOARD_CONFIG", "PORT", "SCOREBOARD_ADDR", "DATA_DIR", "READ_TIMEOUT", "WATCH_INTERVAL", "SCOREBOARD_DEV"} {
+				t.Setenv(name, tt.env[name])
+			}
+
+			cfg, err := load(tt.args)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !tt.want(cfg) {
+				t.Errorf("load(%q) = %+v", tt.args, cfg)
+			}
+		})
+	}
+}
+
+func TestLoadInvalid(t *testing.T) {
+	dir := t.TempDir()
+	tomlPath := filepath.Join(dir, "config.toml")
+	if err := os.WriteFile(tomlPath, []byte(`addr = ":9000"`), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name string
+		args []string
+	}{
+		{"missing config file", []string{"-config", filepath.Join(dir, "missing.yaml")}},
+		{"unsupported format", []string{"-config", tomlPath}},
+		{"tls cert without key", []string{"-tls-cert", "cert.pem"}},
+		{"unknown log level", []string{"-log-level", "verbose"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv("SCOREBOARD_CONFIG", "")
+			if _, err := load(tt.args); err == nil {
+				t.Errorf("load(%q) returned no error", tt.args)
+			}
+		})
+	}
+}
@@ -1,12 +1,16 @@
 package handler
 
 import (
+	"crypto/subtle"
 	"encoding/json"
+	"fmt"
 	"html/template"
+	"io/fs"
 	"log"
 	"net/http"
 	"strconv"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/lllllan02/scoreboard/internal/service"
@@ -21,9 +25,59 @@ var (
 		},
 	}
 
-	templates = template.Must(template.New("").Funcs(funcMap).ParseGlob("web/templates/*.html"))
+	// 页面模板，由 LoadTemplates 加载
+	templates *template.Template
+	// 开发模式下每次渲染前重新从 templateFS 加载模板
+	templateFS   fs.FS
+	templateDev  bool
+	templateLock sync.Mutex
 )
 
+// LoadTemplates 加载 fsys 根目录下的页面模板，模板中可以通过 asset 函数获取静态文件的 URL
+// dev 为 true 时每次渲染都重新加载，修改模板后无需重启
+func LoadTemplates(fsys fs.FS, static *StaticFiles, dev bool) error {
+	funcMap["asset"] = static.URL
+
+	parsed, err := parseTemplates(fsys)
+	if err != nil {
+		return err
+	}
+
+	templateLock.Lock()
+	defer templateLock.Unlock()
+
+	templates = parsed
+	templateFS = fsys
+	templateDev = dev
+	return nil
+}
+
+// parseTemplates 解析 fsys 根目录下的所有模板
+func parseTemplates(fsys fs.FS) (*template.Template, error) {
+	parsed, err := template.New("").Funcs(funcMap).ParseFS(fsys, "*.html")
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse templates: %w", err)
+	}
+	return parsed, nil
+}
+
+// executeTemplate 渲染页面模板
+func executeTemplate(w http.ResponseWriter, name string, data interface{}) error {
+	templateLock.Lock()
+	if templateDev {
+		parsed, err := parseTemplates(templateFS)
+		if err != nil {
+			templateLock.Unlock()
+			return err
+		}
+		templates = parsed
+	}
+	current := templates
+	templateLock.Unlock()
+
+	return current.ExecuteTemplate(w, name, data)
+}
+
 // IndexHandler 处理首页请求
 func IndexHandler(svc *service.ScoreboardService) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
@@ -44,7 +98,7 @@ func IndexHandler(svc *service.ScoreboardService) http.HandlerFunc {
 			"Contests": contests,
 		}
 
-		if err := templates.ExecuteTemplate(w, "index.html", data); err != nil {
+		if err := executeTemplate(w, "index.html", data); err != nil {
 			log.Printf("Error rendering template: %v", err)
 			http.Error(w, "Internal Server Error", http.StatusInternalServerError)
 		}
@@ -81,7 +135,7 @@ func ContestHandler(svc *service.ScoreboardService) http.HandlerFunc {
 			"ProblemCount": contest.ProblemCount,
 		}
 
-		if err := templates.ExecuteTemplate(w, "contest.html", data); err != nil {
+		if err := executeTemplate(w, "contest.html", data); err != nil {
 			log.Printf("Error rendering template: %v", err)
 			http.Error(w, "Internal Server Error", http.StatusInternalServerError)
 		}
@@ -105,8 +159,43 @@ func ScoreboardHandler(svc *service.ScoreboardService) http.HandlerFunc {
 		// 获取筛选参数
 		filter := r.URL.Query().Get("filter")
 
+		// 获取截至时刻参数（Unix时间戳，秒），未指定或无效时使用当前时间
+		var asOf int64
+		if timeStr := r.URL.Query().Get("time"); timeStr != "" {
+			if parsedTime, err := strconv.ParseInt(timeStr, 10, 64); err == nil && parsedTime > 0 {
+				asOf = parsedTime
+			}
+		}
+
+		// 指定 since 时只返回相对该版本发生变化的队伍
+		if sinceStr := r.URL.Query().Get("since"); sinceStr != "" {
+			if r.URL.Query().Has("time") {
+				http.Error(w, "The since and time parameters cannot be combined", http.StatusBadRequest)
+				return
+			}
+			since, err := strconv.ParseUint(sinceStr, 10, 64)
+			if err != nil {
+				http.Error(w, "Invalid since parameter", http.StatusBadRequest)
+				return
+			}
+
+			delta, err := svc.GetScoreboardDelta(contestID, filter, since)
+			if err != nil {
+				log.Printf("获取记分板增量失败: %v", err)
+				if strings.Contains(err.Error(), "not found") {
+					http.NotFound(w, r)
+				} else {
+					http.Error(w, "Internal Server Error", http.StatusInternalServerError)
+				}
+				return
+			}
+
+			respondJSON(w, http.StatusOK, delta)
+			return
+		}
+
 		// 使用统一的筛选方法获取数据
-		results, contest, err := svc.GetScoreboardWithFilter(contestID, filter)
+		scoreboard, err := svc.GetScoreboardWithFilter(contestID, filter, asOf)
 		if err != nil {
 			log.Printf("获取记分板数据失败: %v", err)
 			if strings.Contains(err.Error(), "not found") {
@@ -117,15 +206,69 @@ func ScoreboardHandler(svc *service.ScoreboardService) http.HandlerFunc {
 			return
 		}
 
-		log.Printf("获取到结果记录，共 %d 条", len(results))
+		log.Printf("获取到结果记录，共 %d 条", len(scoreboard.Results))
 
-		// 返回完整的contest对象和结果
-		response := map[string]interface{}{
-			"contest": contest,
-			"results": results,
+		// 返回完整的contest对象、结果、一血列表和数据异常提示
+		respondJSON(w, http.StatusOK, scoreboard)
+	}
+}
+
+// OrganizationsHandler 处理获取学校排名的API请求
+// 支持参数：filter、time（同记分板），mode（best 按最好队伍 / top 按前 K 支队伍总成绩），
+// k（mode 为 top 时的队伍数，默认 3），exclude_unofficial（为 true 时排除打星队伍）
+func OrganizationsHandler(svc *service.ScoreboardService) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		contestID := strings.TrimPrefix(r.URL.Path, "/api/organizations/")
+		log.Printf("获取比赛学校排名: %s", contestID)
+
+		query := r.URL.Query()
+
+		// 获取截至时刻参数
+		var asOf int64
+		if timeStr := query.Get("time"); timeStr != "" {
+			if parsedTime, err := strconv.ParseInt(timeStr, 10, 64); err == nil && parsedTime > 0 {
+				asOf = parsedTime
+			}
 		}
 
-		respondJSON(w, http.StatusOK, response)
+		// 解析排名选项
+		opts := service.OrganizationOptions{
+			Mode: query.Get("mode"),
+			TopK: 3,
+		}
+		switch opts.Mode {
+		case "", service.OrganizationRankBest, service.OrganizationRankTopK:
+		default:
+			http.Error(w, "Bad Request", http.StatusBadRequest)
+			return
+		}
+		if kStr := query.Get("k"); kStr != "" {
+			parsedK, err := strconv.Atoi(kStr)
+			if err != nil || parsedK <= 0 {
+				http.Error(w, "Bad Request", http.StatusBadRequest)
+				return
+			}
+			opts.TopK = parsedK
+		}
+		if excludeStr := query.Get("exclude_unofficial"); excludeStr != "" {
+			opts.ExcludeUnofficial, _ = strconv.ParseBool(excludeStr)
+		}
+
+		organizations, contest, err := svc.GetOrganizationRanking(contestID, query.Get("filter"), asOf, opts)
+		if err != nil {
+			log.Printf("获取学校排名失败: %v", err)
+			if strings.Contains(err.Error(), "not found") {
+				http.NotFound(w, r)
+			} else {
+				http.Error(w, "Internal Server Error", http.StatusInternalServerError)
+			}
+			return
+		}
+
+		respondJSON(w, http.StatusOK, map[string]interface{}{
+			"contest":       contest,
+			"organizations": organizations,
+		})
 	}
 }
 
@@ -243,3 +386,230 @@ func SubmissionsHandler(svc *service.ScoreboardService) http.HandlerFunc {
 		respondJSON(w, http.StatusOK, response)
 	}
 }
+
+// UnfreezeHandler 处理解除封榜的管理接口
+// POST 解除封榜（可通过 time 参数指定解除时刻，默认当前时间），DELETE 恢复封榜
+// 请求需要携带 Authorization: Bearer <token>，token 为空时接口不可用
+func UnfreezeHandler(svc *service.ScoreboardService, token string) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		// 校验管理员令牌
+		if !checkAdminToken(w, r, token) {
+			return
+		}
+
+		contestID := strings.TrimPrefix(r.URL.Path, "/api/admin/unfreeze/")
+		if contestID == "" {
+			http.NotFound(w, r)
+			return
+		}
+
+		var unfreezeTime int64
+		switch r.Method {
+		case http.MethodPost:
+			unfreezeTime = time.Now().Unix()
+			if timeStr := r.URL.Query().Get("time"); timeStr != "" {
+				parsedTime, err := strconv.ParseInt(timeStr, 10, 64)
+				if err != nil || parsedTime <= 0 {
+					http.Error(w, "Bad Request", http.StatusBadRequest)
+					return
+				}
+				unfreezeTime = parsedTime
+			}
+		case http.MethodDelete:
+			unfreezeTime = 0
+		default:
+			w.Header().Set("Allow", "POST, DELETE")
+			http.Error(w, "Method Not Allowed", http.StatusMethodNotAllowed)
+			return
+		}
+
+		contest, err := svc.SetUnfreezeTime(contestID, unfreezeTime)
+		if err != nil {
+			log.Printf("更新封榜状态失败: %v", err)
+			if strings.Contains(err.Error(), "not found") {
+				http.NotFound(w, r)
+			} else {
+				http.Error(w, "Internal Server Error", http.StatusInternalServerError)
+			}
+			return
+		}
+
+		log.Printf("比赛 %s 解除封榜时刻已更新为 %d", contestID, unfreezeTime)
+		respondJSON(w, http.StatusOK, map[string]interface{}{
+			"contest_id":    contest.ID,
+			"unfreeze_time": contest.UnfreezeTime,
+		})
+	}
+}
+
+// checkAdminToken 校验请求携带的管理员令牌，校验失败时写入错误响应并返回 false
+func checkAdminToken(w http.ResponseWriter, r *http.Request, token string) bool {
+	if token == "" {
+		http.Error(w, "Forbidden", http.StatusForbidden)
+		return false
+	}
+	provided := strings.TrimPrefix(r.Header.Get("Authorization"), "Bearer ")
+	if subtle.ConstantTimeCompare([]byte(provided), []byte(token)) != 1 {
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return false
+	}
+	return true
+}
+
+// ResolverHandler 处理颁奖仪式揭晓过程的API请求
+// cursor 参数表示已经执行的步骤数（默认 0，即封榜记分板），响应包含该位置的记分板、
+// 刚执行的一步和下一步，客户端通过增减 cursor 逐步推进或回退
+// 揭晓过程会提前暴露封榜后的结果，因此需要管理员令牌
+func ResolverHandler(svc *service.ScoreboardService, token string) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if !checkAdminToken(w, r, token) {
+			return
+		}
+
+		contestID := strings.TrimPrefix(r.URL.Path, "/api/resolver/")
+		log.Printf("获取比赛揭晓过程: %s", contestID)
+
+		resolver, contest, err := svc.GetResolver(contestID)
+		if err != nil {
+			log.Printf("生成揭晓过程失败: %v", err)
+			if strings.Contains(err.Error(), "not found") {
+				http.NotFound(w, r)
+			} else {
+				http.Error(w, "Internal Server Error", http.StatusInternalServerError)
+			}
+			return
+		}
+
+		// 解析游标参数
+		cursor := 0
+		if cursorStr := r.URL.Query().Get("cursor"); cursorStr != "" {
+			parsedCursor, err := strconv.Atoi(cursorStr)
+			if err != nil || parsedCursor < 0 || parsedCursor > len(resolver.Steps) {
+				http.Error(w, "Bad Request", http.StatusBadRequest)
+				return
+			}
+			cursor = parsedCursor
+		}
+
+		results, err := resolver.BoardAt(cursor)
+		if err != nil {
+			log.Printf("获取揭晓记分板失败: %v", err)
+			http.Error(w, "Internal Server Error", http.StatusInternalServerError)
+			return
+		}
+
+		response := map[string]interface{}{
+			"contest":     contest,
+			"results":     results,
+			"cursor":      cursor,
+			"total_steps": len(resolver.Steps),
+		}
+		if cursor > 0 {
+			response["step"] = resolver.Steps[cursor-1]
+		}
+		if cursor < len(resolver.Steps) {
+			response["next"] = resolver.Steps[cursor]
+		}
+
+		respondJSON(w, http.StatusOK, response)
+	}
+}
+
+// sseHeartbeat SSE 连接的心跳间隔，防止代理断开空闲连接
+const sseHeartbeat = 15 * time.Second
+
+// EventsHandler 以 Server-Sent Events 推送比赛的提交、评测结果、排名和一血变化
+// 客户端断线重连时通过 Last-Event-ID 请求头（或 last_event_id 参数）续传
+func EventsHandler(feed *service.Feed) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		contestID := strings.TrimPrefix(r.URL.Path, "/api/events/")
+		if contestID == "" {
+			http.NotFound(w, r)
+			return
+		}
+
+		flusher, ok := w.(http.Flusher)
+		if !ok {
+			http.Error(w, "Streaming unsupported", http.StatusInternalServerError)
+			return
+		}
+
+		// 获取客户端收到的最后一条消息的 ID
+		lastEventID := r.Header.Get("Last-Event-ID")
+		if lastEventID == "" {
+			lastEventID = r.URL.Query().Get("last_event_id")
+		}
+		var lastID uint64
+		if lastEventID != "" {
+			if parsedID, err := strconv.ParseUint(lastEventID, 10, 64); err == nil {
+				lastID = parsedID
+			}
+		}
+
+		backlog, messages, cancel, err := feed.Subscribe(contestID, lastID)
+		if err != nil {
+			log.Printf("订阅比赛消息失败: %v", err)
+			if strings.Contains(err.Error(), "not found") {
+				http.NotFound(w, r)
+			} else {
+				http.Error(w, "Internal Server Error", http.StatusInternalServerError)
+			}
+			return
+		}
+		defer cancel()
+
+		w.Header().Set("Content-Type", "text/event-stream")
+		w.Header().Set("Cache-Control", "no-cache")
+		w.Header().Set("Connection", "keep-alive")
+		w.Header().Set("X-Accel-Buffering", "no")
+
+		// 事件流是长连接，不受服务器写超时的限制
+		http.NewResponseController(w).SetWriteDeadline(time.Time{})
+		w.WriteHeader(http.StatusOK)
+
+		// 建议客户端断线后 3 秒重连
+		if _, err := w.Write([]byte("retry: 3000\n\n")); err != nil {
+			return
+		}
+		for _, msg := range backlog {
+			if err := writeEvent(w, msg); err != nil {
+				return
+			}
+		}
+		flusher.Flush()
+
+		heartbeat := time.NewTicker(sseHeartbeat)
+		defer heartbeat.Stop()
+
+		for {
+			select {
+			case <-r.Context().Done():
+				return
+			case msg, ok := <-messages:
+				if !ok {
+					// 服务关闭或客户端处理不过来，客户端会带着 Last-Event-ID 重连
+					return
+				}
+				if err := writeEvent(w, msg); err != nil {
+					return
+				}
+				flusher.Flush()
+			case <-heartbeat.C:
+				if _, err := w.Write([]byte(": ping\n\n")); err != nil {
+					return
+				}
+				flusher.Flush()
+			}
+		}
+	}
+}
+
+// writeEvent 按 SSE 格式写出一条消息
+func writeEvent(w http.ResponseWriter, msg service.FeedMessage) error {
+	data, err := json.Marshal(msg)
+	if err != nil {
+		return err
+	}
+	_, err = fmt.Fprintf(w, "id: %d\nevent: %s\ndata: %s\n\n", msg.ID, msg.Type, data)
+	return err
+}
@@ -0,0 +1,104 @@
+package handler
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io/fs"
+	"net/http"
+	"net/url"
+	"path"
+	"strings"
+)
+
+// StaticFiles 提供静态文件，文件名中带有内容哈希的 URL 可以被浏览器长期缓存
+// 例如 js/main.js 的 URL 为 /static/js/main.1a2b3c4d5e.js，文件内容变化后 URL 随之变化
+type StaticFiles struct {
+	fsys      fs.FS
+	dev       bool
+	hashed    map[string]string // 原始路径 -> 带哈希的路径
+	originals map[string]string // 带哈希的路径 -> 原始路径
+	etags     map[string]string // 原始路径 -> ETag
+	files     http.Handler
+}
+
+// staticCacheControl 带哈希的 URL 内容不会变化，缓存一年
+const staticCacheControl = "public, max-age=31536000, immutable"
+
+// NewStaticFiles 创建静态文件服务并计算所有文件的内容哈希
+// dev 为 true 时不使用哈希，每次请求都读取最新的文件，适合开发时直接修改磁盘上的文件
+func NewStaticFiles(fsys fs.FS, dev bool) (*StaticFiles, error) {
+	s := &StaticFiles{
+		fsys:      fsys,
+		dev:       dev,
+		hashed:    make(map[string]string),
+		originals: make(map[string]string),
+		etags:     make(map[string]string),
+		files:     http.FileServer(http.FS(fsys)),
+	}
+	if dev {
+		return s, nil
+	}
+
+	err := fs.WalkDir(fsys, ".", func(name string, d fs.DirEntry, err error) error {
+		if err != nil || d.IsDir() {
+			return err
+		}
+
+		data, err := fs.ReadFile(fsys, name)
+		if err != nil {
+			return err
+		}
+		sum := sha256.Sum256(data)
+		hash := hex.EncodeToString(sum[:])[:10]
+
+		ext := path.Ext(name)
+		hashedName := strings.TrimSuffix(name, ext) + "." + hash + ext
+		s.hashed[name] = hashedName
+		s.originals[hashedName] = name
+		s.etags[name] = `"` + hash + `"`
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to hash static files: %w", err)
+	}
+
+	return s, nil
+}
+
+// URL 获取静态文件的访问路径，name 为相对静态文件目录的路径
+func (s *StaticFiles) URL(name string) string {
+	name = strings.TrimPrefix(name, "/")
+	if hashedName, ok := s.hashed[name]; ok {
+		return "/static/" + hashedName
+	}
+	return "/static/" + name
+}
+
+// ServeHTTP 处理 /static/ 下的请求
+func (s *StaticFiles) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	name := strings.TrimPrefix(r.URL.Path, "/static/")
+
+	switch {
+	case s.dev:
+		w.Header().Set("Cache-Control", "no-cache")
+	case s.originals[name] != "":
+		// 带哈希的路径，内容不会变化
+		name = s.originals[name]
+		w.Header().Set("Cache-Control", staticCacheControl)
+	default:
+		// 不带哈希的路径，每次都需要验证
+		w.Header().Set("Cache-Control", "no-cache")
+		if etag, ok := s.etags[name]; ok {
+			w.Header().Set("ETag", etag)
+		}
+	}
+
+	r2 := new(http.Request)
+	*r2 = *r
+	r2.URL = new(url.URL)
+	*r2.URL = *r.URL
+	r2.URL.Path = "/" + name
+	r2.URL.RawPath = ""
+	s.files.ServeHTTP(w, r2)
+}
@@ -0,0 +1,80 @@
+package handler
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"regexp"
+	"testing"
+	"testing/fstest"
+)
+
+func TestStaticFiles(t *testing.T) {
+	fsys := fstest.MapFS{
+		"js/main.js":   {Data: []byte("console.log(1)")},
+		"css/main.css": {Data: []byte("body {}")},
+	}
+	static, err := NewStaticFiles(fsys, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	hashedURL := static.URL("/js/main.js")
+	if !regexp.MustCompile(`^/static/js/main\.[0-9a-f]{10}\.js$`).MatchString(hashedURL) {
+		t.Fatalf("URL(js/main.js) = %s, want a hashed URL", hashedURL)
+	}
+	if url := static.URL("js/missing.js"); url != "/static/js/missing.js" {
+		t.Errorf("URL(js/missing.js) = %s, want the unhashed URL", url)
+	}
+
+	// 内容变化后 URL 随之变化
+	fsys["js/main.js"] = &fstest.MapFile{Data: []byte("console.log(2)")}
+	changed, err := NewStaticFiles(fsys, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if changed.URL("js/main.js") == hashedURL {
+		t.Error("hashed URL did not change with the file content")
+	}
+
+	tests := []struct {
+		name       string
+		path       string
+		wantStatus int
+		wantBody   string
+		wantCache  string
+		wantETag   bool
+		dev        bool
+	}{
+		{"hashed url", changed.URL("js/main.js"), http.StatusOK, "console.log(2)", staticCacheControl, false, false},
+		{"original url", "/static/js/main.js", http.StatusOK, "console.log(2)", "no-cache", true, false},
+		{"stale hash", hashedURL, http.StatusNotFound, "", "no-cache", false, false},
+		{"dev mode", "/static/css/main.css", http.StatusOK, "body {}", "no-cache", false, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			static := changed
+			if tt.dev {
+				if static, err = NewStaticFiles(fsys, true); err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			rec := httptest.NewRecorder()
+			static.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, tt.path, nil))
+
+			if rec.Code != tt.wantStatus {
+				t.Fatalf("status = %d, want %d", rec.Code, tt.wantStatus)
+			}
+			if tt.wantBody != "" && rec.Body.String() != tt.wantBody {
+				t.Errorf("body = %q, want %q", rec.Body.String(), tt.wantBody)
+			}
+			if got := rec.Header().Get("Cache-Control"); got != tt.wantCache {
+				t.Errorf("Cache-Control = %q, want %q", got, tt.wantCache)
+			}
+			if got := rec.Header().Get("ETag"); (got != "") != tt.wantETag {
+				t.Errorf("ETag = %q, want set: %v", got, tt.wantETag)
+			}
+		})
+	}
+}
@@ -0,0 +1,301 @@
+package model
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+// DefaultDataRoot 默认的数据目录
+const DefaultDataRoot = "data"
+
+// FileStore 基于文件系统的比赛存储，每个比赛一个目录，比赛ID即相对数据目录的路径
+// 目录下有 config.json、team.json、run.json 和可选的 freeze.json，数据目录下有 directory.json
+type FileStore struct {
+	root string
+}
+
+// NewFileStore 创建以 root 为数据目录的存储
+func NewFileStore(root string) *FileStore {
+	if root == "" {
+		root = DefaultDataRoot
+	}
+	return &FileStore{root: root}
+}
+
+// Root 获取数据目录
+func (s *FileStore) Root() string {
+	return s.root
+}
+
+// ListContests 扫描数据目录，返回所有包含 config.json 的比赛ID
+func (s *FileStore) ListContests() ([]string, error) {
+	var contestIDs []string
+	err := filepath.Walk(s.root, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+
+		if !info.IsDir() && info.Name() == "config.json" {
+			relPath, err := filepath.Rel(s.root, filepath.Dir(path))
+			if err != nil {
+				return err
+			}
+			contestIDs = append(contestIDs, filepath.ToSlash(relPath))
+		}
+
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to scan data directory: %w", err)
+	}
+
+	return contestIDs, nil
+}
+
+// Stamp 用数据文件的修改时间和大小作为版本标记
+func (s *FileStore) Stamp(contestID string) (ContestStamp, error) {
+	var stamp ContestStamp
+	var err error
+
+	if stamp.Config, err = s.fileStamp(contestID, "config.json"); err != nil {
+		return ContestStamp{}, err
+	}
+	freeze, err := s.fileStamp(contestID, "freeze.json")
+	if err != nil && !errors.Is(err, ErrNotExist) {
+		return ContestStamp{}, err
+	}
+	stamp.Config += "/" + freeze
+
+	if stamp.Teams, err = s.fileStamp(contestID, "team.json"); err != nil {
+		return ContestStamp{}, err
+	}
+	if stamp.Runs, err = s.fileStamp(contestID, "run.json"); err != nil {
+		return ContestStamp{}, err
+	}
+
+	return stamp, nil
+}
+
+// fileStamp 获取数据文件的修改时间和大小
+func (s *FileStore) fileStamp(contestID string, name string) (string, error) {
+	info, err := os.Stat(s.path(contestID, name))
+	if errors.Is(err, os.ErrNotExist) {
+		return "", fmt.Errorf("%s %w", name, ErrNotExist)
+	}
+	if err != nil {
+		return "", fmt.Errorf("failed to stat %s: %w", name, err)
+	}
+	return fmt.Sprintf("%d:%d", info.ModTime().UnixNano(), info.Size()), nil
+}
+
+// LoadConfig 读取 config.json
+func (s *FileStore) LoadConfig(contestID string) (*Contest, error) {
+	var contest Contest
+	if err := s.readJSON(contestID, "config.json", &contest); err != nil {
+		return nil, err
+	}
+	return &contest, nil
+}
+
+// LoadTeams 读取 team.json
+func (s *FileStore) LoadTeams(contestID string) (map[string]*Team, error) {
+	var teams map[string]*Team
+	if err := s.readJSON(contestID, "team.json", &teams); err != nil {
+		return nil, err
+	}
+	return teams, nil
+}
+
+// LoadRuns 读取 run.json
+func (s *FileStore) LoadRuns(contestID string) ([]*Run, error) {
+	var runs []*Run
+	if err := s.readJSON(contestID, "run.json", &runs); err != nil {
+		return nil, err
+	}
+	return runs, nil
+}
+
+// LoadRaw 读取 config.json、team.json 和 run.json 的原始内容
+func (s *FileStore) LoadRaw(contestID string) (config, teams, runs []byte, err error) {
+	if config, err = s.readFile(contestID, "config.json"); err != nil {
+		return nil, nil, nil, err
+	}
+	if teams, err = s.readFile(contestID, "team.json"); err != nil {
+		return nil, nil, nil, err
+	}
+	if runs, err = s.readFile(contestID, "run.json"); err != nil {
+		return nil, nil, nil, err
+	}
+	return config, teams, runs, nil
+}
+
+// ImportContest 把原始数据写入比赛目录，先写队伍和提交，最后写配置
+func (s *FileStore) ImportContest(contestID string, config, teams, runs []byte) error {
+	if err := os.MkdirAll(filepath.Dir(s.path(contestID, "config.json")), 0755); err != nil {
+		return fmt.Errorf("failed to create contest directory: %w", err)
+	}
+
+	files := []struct {
+		name string
+		data []byte
+	}{
+		{"team.json", teams},
+		{"run.json", runs},
+		{"config.json", config},
+	}
+	for _, file := range files {
+		if err := writeFileAtomic(s.path(contestID, file.name), file.data, 0644); err != nil {
+			return fmt.Errorf("failed to write %s: %w", file.name, err)
+		}
+	}
+
+	return nil
+}
+
+// LoadFreezeState 读取 freeze.json，文件不存在时返回 nil
+func (s *FileStore) LoadFreezeState(contestID string) (*FreezeState, error) {
+	var state FreezeState
+	err := s.readJSON(contestID, "freeze.json", &state)
+	if errors.Is(err, ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &state, nil
+}
+
+// SaveFreezeState 写入 freeze.json
+func (s *FileStore) SaveFreezeState(contestID string, state *FreezeState) error {
+	data, err := json.MarshalIndent(state, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal freeze state: %w", err)
+	}
+
+	if err := writeFileAtomic(s.path(contestID, "freeze.json"), data, 0644); err != nil {
+		return fmt.Errorf("failed to write freeze.json: %w", err)
+	}
+	return nil
+}
+
+// LoadDirectory 读取数据目录下的 directory.json
+func (s *FileStore) LoadDirectory() (*ContestDirectory, error) {
+	data, err := os.ReadFile(filepath.Join(s.root, "directory.json"))
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, fmt.Errorf("directory.json %w", ErrNotExist)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read directory.json: %w", err)
+	}
+
+	var directory ContestDirectory
+	if err := json.Unmarshal(data, &directory); err != nil {
+		return nil, fmt.Errorf("failed to parse directory.json: %w", err)
+	}
+	return &directory, nil
+}
+
+// UpdateDirectory 在目录锁的保护下读取、修改并写回 directory.json
+// 目录锁是数据目录下 directory.json.lock 文件上的建议锁，同时约束其他进程（如爬虫）
+func (s *FileStore) UpdateDirectory(update func(directory *ContestDirectory) error) error {
+	if err := os.MkdirAll(s.root, 0755); err != nil {
+		return fmt.Errorf("failed to create data directory: %w", err)
+	}
+
+	unlock, err := lockFile(filepath.Join(s.root, "directory.json.lock"))
+	if err != nil {
+		return fmt.Errorf("failed to lock directory: %w", err)
+	}
+	defer unlock()
+
+	directory, err := s.LoadDirectory()
+	if errors.Is(err, ErrNotExist) {
+		directory = &ContestDirectory{}
+	} else if err != nil {
+		return err
+	}
+	if directory.Contests == nil {
+		directory.Contests = make(map[string]ContestInfo)
+	}
+
+	if err := update(directory); err != nil {
+		return err
+	}
+
+	data, err := json.MarshalIndent(directory, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal directory data: %w", err)
+	}
+
+	if err := writeFileAtomic(filepath.Join(s.root, "directory.json"), data, 0644); err != nil {
+		return fmt.Errorf("failed to write directory.json: %w", err)
+	}
+	return nil
+}
+
+// path 获取比赛目录下数据文件的路径
+func (s *FileStore) path(contestID string, name string) string {
+	return filepath.Join(s.root, filepath.FromSlash(contestID), name)
+}
+
+// readFile 读取比赛目录下的数据文件
+func (s *FileStore) readFile(contestID string, name string) ([]byte, error) {
+	data, err := os.ReadFile(s.path(contestID, name))
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, fmt.Errorf("%s %w", name, ErrNotExist)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", name, err)
+	}
+	return data, nil
+}
+
+// readJSON 读取并解析比赛目录下的数据文件
+func (s *FileStore) readJSON(contestID string, name string, v interface{}) error {
+	data, err := s.readFile(contestID, name)
+	if err != nil {
+		return err
+	}
+
+	if err := json.Unmarshal(data, v); err != nil {
+		return fmt.Errorf("failed to parse %s: %w", name, err)
+	}
+	return nil
+}
+
+// writeFileAtomic 先写入同目录下的临时文件再重命名，读取方不会看到写了一半的文件
+func writeFileAtomic(path string, data []byte, perm os.FileMode) error {
+	tmp, err := os.CreateTemp(filepath.Dir(path), "."+filepath.Base(path)+".*.tmp")
+	if err != nil {
+		return err
+	}
+	tmpPath := tmp.Name()
+
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		os.Remove(tmpPath)
+		return err
+	}
+	if err := tmp.Sync(); err != nil {
+		tmp.Close()
+		os.Remove(tmpPath)
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		os.Remove(tmpPath)
+		return err
+	}
+	if err := os.Chmod(tmpPath, perm); err != nil {
+		os.Remove(tmpPath)
+		return err
+	}
+
+	if err := os.Rename(tmpPath, path); err != nil {
+		os.Remove(tmpPath)
+		return err
+	}
+	return nil
+}
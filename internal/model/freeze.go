@@ -0,0 +1,55 @@
+package model
+
+import "fmt"
+
+// FreezeState 封榜状态，文件存储中保存在比赛目录的 freeze.json 中
+// 与比赛配置分开保存，避免重新爬取配置时覆盖
+type FreezeState struct {
+	UnfreezeTime int64 `json:"unfreeze_time"` // 解除封榜的时刻（Unix时间戳，秒），0 表示尚未解除
+}
+
+// IsFrozenAt 判断记分板在 asOf 时刻是否处于封榜状态
+// 封榜从比赛结束前 FrozenTime 秒开始，一直持续到解除封榜为止，与比赛是否结束无关
+func (c *Contest) IsFrozenAt(asOf int64) bool {
+	if c.FrozenTime <= 0 || asOf < c.EndTime-c.FrozenTime {
+		return false
+	}
+	return c.UnfreezeTime == 0 || asOf < c.UnfreezeTime
+}
+
+// IsBoardFrozen 判断截至 asOf 时刻的记分板在 now 时刻是否应当封榜
+// 晚于 now 的 asOf（包括 LatestTime）按 now 判断是否已经解除封榜，
+// 安排在将来的解除封榜时刻到达之前记分板保持封榜
+func (c *Contest) IsBoardFrozen(asOf, now int64) bool {
+	if asOf <= now {
+		return c.IsFrozenAt(asOf)
+	}
+	return c.IsFrozenAt(max(now, c.EndTime))
+}
+
+// loadFreezeState 加载比赛的封榜状态，从未保存过时视为尚未解除封榜
+func (c *Contest) loadFreezeState() error {
+	state, err := c.store.LoadFreezeState(c.ID)
+	if err != nil {
+		return err
+	}
+
+	if state != nil {
+		c.UnfreezeTime = state.UnfreezeTime
+	}
+	return nil
+}
+
+// SetUnfreezeTime 设置并保存解除封榜的时刻，传入 0 表示恢复封榜
+func (c *Contest) SetUnfreezeTime(unfreezeTime int64) error {
+	if c.store == nil {
+		return fmt.Errorf("store not set, cannot save freeze state")
+	}
+
+	if err := c.store.SaveFreezeState(c.ID, &FreezeState{UnfreezeTime: unfreezeTime}); err != nil {
+		return err
+	}
+
+	c.UnfreezeTime = unfreezeTime
+	return nil
+}
@@ -0,0 +1,71 @@
+package model_test
+
+import (
+	"testing"
+
+	"github.com/lllllan02/scoreboard/internal/model"
+	"github.com/lllllan02/scoreboard/internal/model/modeltest"
+)
+
+func TestIsFrozenAt(t *testing.T) {
+	c := modeltest.NewContest()
+	c.FrozenTime = 3600
+	frozenStart := c.EndTime - c.FrozenTime
+
+	tests := []struct {
+		name         string
+		frozenTime   int64
+		unfreezeTime int64
+		asOf         int64
+		want         bool
+	}{
+		{"no freeze", 0, 0, c.EndTime, false},
+		{"before the freeze", 3600, 0, frozenStart - 1, false},
+		{"during the freeze", 3600, 0, frozenStart, true},
+		{"after the contest until unfrozen", 3600, 0, c.EndTime + 86400, true},
+		{"before the unfreeze time", 3600, c.EndTime + 600, c.EndTime + 599, true},
+		{"at the unfreeze time", 3600, c.EndTime + 600, c.EndTime + 600, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c.FrozenTime = tt.frozenTime
+			c.UnfreezeTime = tt.unfreezeTime
+			if got := c.IsFrozenAt(tt.asOf); got != tt.want {
+				t.Errorf("IsFrozenAt(%d) = %v, want %v", tt.asOf, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestIsBoardFrozen(t *testing.T) {
+	c := modeltest.NewContest()
+	c.FrozenTime = 3600
+	frozenStart := c.EndTime - c.FrozenTime
+
+	tests := []struct {
+		name         string
+		unfreezeTime int64
+		asOf, now    int64
+		want         bool
+	}{
+		{"before the freeze", 0, frozenStart - 1, c.EndTime + 100, false},
+		{"during the freeze", 0, frozenStart, c.EndTime + 100, true},
+		{"never unfrozen", 0, model.LatestTime, c.EndTime + 100, true},
+		{"latest during the contest", 0, model.LatestTime, frozenStart + 10, true},
+		{"latest hides later runs before the freeze starts", 0, model.LatestTime, frozenStart - 10, true},
+		{"unfrozen in the past", c.EndTime + 50, model.LatestTime, c.EndTime + 100, false},
+		{"unfreeze scheduled in the future", c.EndTime + 200, model.LatestTime, c.EndTime + 100, true},
+		{"as of a time before the unfreeze", c.EndTime + 50, c.EndTime + 10, c.EndTime + 100, true},
+		{"future as of before a scheduled unfreeze", c.EndTime + 200, c.EndTime + 300, c.EndTime + 100, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c.UnfreezeTime = tt.unfreezeTime
+			if got := c.IsBoardFrozen(tt.asOf, tt.now); got != tt.want {
+				t.Errorf("IsBoardFrozen(%d, %d) = %v, want %v", tt.asOf, tt.now, got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,47 @@
+//go:build !unix
+
+package model
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"time"
+)
+
+const (
+	// lockRetryInterval 锁被占用时重试的间隔
+	lockRetryInterval = 50 * time.Millisecond
+	// lockStaleAge 锁文件存在超过该时长时视为持有锁的进程已经异常退出，目录更新通常在毫秒内完成
+	lockStaleAge = 30 * time.Second
+	// lockTimeout 等待锁的最长时间
+	lockTimeout = time.Minute
+)
+
+// lockFile 通过独占创建 path 获取排他锁，阻塞直到获取成功或超时，返回释放锁的函数
+// 持有锁的进程异常退出后留下的锁文件在 lockStaleAge 之后被清理
+func lockFile(path string) (func(), error) {
+	deadline := time.Now().Add(lockTimeout)
+	for {
+		f, err := os.OpenFile(path, os.O_CREATE|os.O_EXCL|os.O_RDWR, 0644)
+		if err == nil {
+			f.Close()
+			return func() { os.Remove(path) }, nil
+		}
+		if !errors.Is(err, os.ErrExist) {
+			return nil, err
+		}
+
+		if info, err := os.Stat(path); err == nil && time.Since(info.ModTime()) > lockStaleAge {
+			if err := os.Remove(path); err != nil && !errors.Is(err, os.ErrNotExist) {
+				return nil, fmt.Errorf("failed to remove stale lock %s: %w", path, err)
+			}
+			continue
+		}
+
+		if time.Now().After(deadline) {
+			return nil, fmt.Errorf("timed out waiting for lock %s", path)
+		}
+		time.Sleep(lockRetryInterval)
+	}
+}
@@ -0,0 +1,36 @@
+//go:build !unix
+
+package model
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestLockFileRemovesStaleLock(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "directory.json.lock")
+	if err := os.WriteFile(path, nil, 0644); err != nil {
+		t.Fatal(err)
+	}
+	// 模拟持有锁的进程异常退出后留下的锁文件
+	stale := time.Now().Add(-2 * lockStaleAge)
+	if err := os.Chtimes(path, stale, stale); err != nil {
+		t.Fatal(err)
+	}
+
+	start := time.Now()
+	unlock, err := lockFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if elapsed := time.Since(start); elapsed > lockTimeout/2 {
+		t.Errorf("acquiring a stale lock took %v", elapsed)
+	}
+
+	unlock()
+	if _, err := os.Stat(path); !os.IsNotExist(err) {
+		t.Errorf("lock file still exists after unlock: %v", err)
+	}
+}
@@ -0,0 +1,42 @@
+package model
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestLockFileBlocksUntilReleased(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "directory.json.lock")
+	unlock, err := lockFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	acquired := make(chan func())
+	go func() {
+		unlock, err := lockFile(path)
+		if err != nil {
+			t.Error(err)
+			close(acquired)
+			return
+		}
+		acquired <- unlock
+	}()
+
+	select {
+	case <-acquired:
+		t.Fatal("lock acquired while another holder had it")
+	case <-time.After(100 * time.Millisecond):
+	}
+
+	unlock()
+	select {
+	case unlock, ok := <-acquired:
+		if ok {
+			unlock()
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("lock not acquired after the holder released it")
+	}
+}
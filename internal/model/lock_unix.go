@@ -0,0 +1,26 @@
+//go:build unix
+
+package model
+
+import (
+	"os"
+	"syscall"
+)
+
+// lockFile 获取 path 上的排他建议锁，阻塞直到获取成功，返回释放锁的函数
+func lockFile(path string) (func(), error) {
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_RDWR, 0644)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := syscall.Flock(int(f.Fd()), syscall.LOCK_EX); err != nil {
+		f.Close()
+		return nil, err
+	}
+
+	return func() {
+		syscall.Flock(int(f.Fd()), syscall.LOCK_UN)
+		f.Close()
+	}, nil
+}
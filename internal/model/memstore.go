@@ -0,0 +1,243 @@
+package model
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strconv"
+	"sync"
+)
+
+// MemoryStore 保存在内存中的比赛存储，用于测试和临时数据
+// 读取时返回数据的副本，调用方可以自由修改
+type MemoryStore struct {
+	mu        sync.RWMutex
+	revision  uint64
+	contests  map[string]*memoryContest
+	directory *ContestDirectory
+}
+
+// memoryContest 内存中的一个比赛，数据以 JSON 保存，读取时重新解析
+type memoryContest struct {
+	config []byte
+	teams  []byte
+	runs   []byte
+	freeze *FreezeState
+	stamp  ContestStamp
+}
+
+// NewMemoryStore 创建空的内存存储
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{
+		contests: make(map[string]*memoryContest),
+	}
+}
+
+// PutContest 保存比赛的配置、队伍和提交记录
+func (s *MemoryStore) PutContest(contestID string, contest *Contest, teams map[string]*Team, runs []*Run) error {
+	config, err := json.Marshal(contest)
+	if err != nil {
+		return fmt.Errorf("failed to marshal contest: %w", err)
+	}
+	teamData, err := json.Marshal(teams)
+	if err != nil {
+		return fmt.Errorf("failed to marshal teams: %w", err)
+	}
+	runData, err := json.Marshal(runs)
+	if err != nil {
+		return fmt.Errorf("failed to marshal runs: %w", err)
+	}
+
+	return s.ImportContest(contestID, config, teamData, runData)
+}
+
+// PutRuns 替换比赛的提交记录
+func (s *MemoryStore) PutRuns(contestID string, runs []*Run) error {
+	data, err := json.Marshal(runs)
+	if err != nil {
+		return fmt.Errorf("failed to marshal runs: %w", err)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	c, ok := s.contests[contestID]
+	if !ok {
+		return fmt.Errorf("contest %s %w", contestID, ErrNotExist)
+	}
+	c.runs = data
+	c.stamp.Runs = s.nextRevision()
+	return nil
+}
+
+// ListContests 返回所有比赛ID，按ID排序
+func (s *MemoryStore) ListContests() ([]string, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	contestIDs := make([]string, 0, len(s.contests))
+	for contestID := range s.contests {
+		contestIDs = append(contestIDs, contestID)
+	}
+	sort.Strings(contestIDs)
+	return contestIDs, nil
+}
+
+// Stamp 获取比赛数据的版本标记
+func (s *MemoryStore) Stamp(contestID string) (ContestStamp, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	c, ok := s.contests[contestID]
+	if !ok {
+		return ContestStamp{}, fmt.Errorf("contest %s %w", contestID, ErrNotExist)
+	}
+	return c.stamp, nil
+}
+
+// LoadConfig 读取比赛配置
+func (s *MemoryStore) LoadConfig(contestID string) (*Contest, error) {
+	var contest Contest
+	if err := s.decode(contestID, func(c *memoryContest) []byte { return c.config }, &contest); err != nil {
+		return nil, fmt.Errorf("failed to parse config: %w", err)
+	}
+	return &contest, nil
+}
+
+// LoadTeams 读取比赛的队伍
+func (s *MemoryStore) LoadTeams(contestID string) (map[string]*Team, error) {
+	var teams map[string]*Team
+	if err := s.decode(contestID, func(c *memoryContest) []byte { return c.teams }, &teams); err != nil {
+		return nil, fmt.Errorf("failed to parse teams: %w", err)
+	}
+	return teams, nil
+}
+
+// LoadRuns 读取比赛的提交记录
+func (s *MemoryStore) LoadRuns(contestID string) ([]*Run, error) {
+	var runs []*Run
+	if err := s.decode(contestID, func(c *memoryContest) []byte { return c.runs }, &runs); err != nil {
+		return nil, fmt.Errorf("failed to parse runs: %w", err)
+	}
+	return runs, nil
+}
+
+// LoadRaw 读取比赛的原始数据
+func (s *MemoryStore) LoadRaw(contestID string) (config, teams, runs []byte, err error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	c, ok := s.contests[contestID]
+	if !ok {
+		return nil, nil, nil, fmt.Errorf("contest %s %w", contestID, ErrNotExist)
+	}
+	return append([]byte(nil), c.config...), append([]byte(nil), c.teams...), append([]byte(nil), c.runs...), nil
+}
+
+// ImportContest 保存比赛的原始数据，保留已有的封榜状态
+func (s *MemoryStore) ImportContest(contestID string, config, teams, runs []byte) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	c, ok := s.contests[contestID]
+	if !ok {
+		c = &memoryContest{}
+		s.contests[contestID] = c
+	}
+
+	c.config = append([]byte(nil), config...)
+	c.teams = append([]byte(nil), teams...)
+	c.runs = append([]byte(nil), runs...)
+	c.stamp = ContestStamp{Config: s.nextRevision(), Teams: s.nextRevision(), Runs: s.nextRevision()}
+	return nil
+}
+
+// LoadFreezeState 读取比赛的封榜状态
+func (s *MemoryStore) LoadFreezeState(contestID string) (*FreezeState, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	c, ok := s.contests[contestID]
+	if !ok {
+		return nil, fmt.Errorf("contest %s %w", contestID, ErrNotExist)
+	}
+	if c.freeze == nil {
+		return nil, nil
+	}
+	state := *c.freeze
+	return &state, nil
+}
+
+// SaveFreezeState 保存比赛的封榜状态
+func (s *MemoryStore) SaveFreezeState(contestID string, state *FreezeState) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	c, ok := s.contests[contestID]
+	if !ok {
+		return fmt.Errorf("contest %s %w", contestID, ErrNotExist)
+	}
+	saved := *state
+	c.freeze = &saved
+	c.stamp.Config = s.nextRevision()
+	return nil
+}
+
+// LoadDirectory 读取比赛目录
+func (s *MemoryStore) LoadDirectory() (*ContestDirectory, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	if s.directory == nil {
+		return nil, fmt.Errorf("directory %w", ErrNotExist)
+	}
+	return copyDirectory(s.directory), nil
+}
+
+// UpdateDirectory 在锁的保护下修改比赛目录
+func (s *MemoryStore) UpdateDirectory(update func(directory *ContestDirectory) error) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	directory := &ContestDirectory{Contests: make(map[string]ContestInfo)}
+	if s.directory != nil {
+		directory = copyDirectory(s.directory)
+	}
+	if err := update(directory); err != nil {
+		return err
+	}
+
+	s.directory = directory
+	return nil
+}
+
+// decode 解析比赛的一部分数据
+func (s *MemoryStore) decode(contestID string, part func(*memoryContest) []byte, v interface{}) error {
+	s.mu.RLock()
+	c, ok := s.contests[contestID]
+	var data []byte
+	if ok {
+		data = part(c)
+	}
+	s.mu.RUnlock()
+
+	if !ok {
+		return fmt.Errorf("contest %s %w", contestID, ErrNotExist)
+	}
+	return json.Unmarshal(data, v)
+}
+
+// nextRevision 生成新的版本标记，调用方需持有写锁
+func (s *MemoryStore) nextRevision() string {
+	s.revision++
+	return strconv.FormatUint(s.revision, 10)
+}
+
+// copyDirectory 复制比赛目录
+func copyDirectory(directory *ContestDirectory) *ContestDirectory {
+	copied := &ContestDirectory{Contests: make(map[string]ContestInfo, len(directory.Contests))}
+	for contestID, info := range directory.Contests {
+		copied.Contests[contestID] = info
+	}
+	return copied
+}
@@ -1,10 +1,9 @@
 package model
 
 import (
-	"encoding/json"
+	"errors"
 	"fmt"
-	"os"
-	"path/filepath"
+	"sort"
 	"strings"
 	"time"
 )
@@ -16,6 +15,7 @@ type Contest struct {
 	StartTime      int64                     `json:"start_time"`
 	EndTime        int64                     `json:"end_time"`
 	FrozenTime     int64                     `json:"frozen_time"`
+	UnfreezeTime   int64                     `json:"unfreeze_time,omitempty"` // 解除封榜的时刻，来自 freeze.json
 	Penalty        int64                     `json:"penalty"`
 	ProblemCount   int                       `json:"problem_quantity"`
 	ProblemIDs     []string                  `json:"problem_id"`
@@ -29,8 +29,8 @@ type Contest struct {
 	Banner         Banner                    `json:"banner"`
 	Options        ContestOptions            `json:"options"`
 
-	// 存储数据目录，用于按需加载
-	dataDir string `json:"-"`
+	// 比赛所在的存储，用于按需加载
+	store ContestStore `json:"-"`
 }
 
 // BalloonColor 表示气球颜色
@@ -51,7 +51,12 @@ type Banner struct {
 
 // ContestOptions 比赛选项
 type ContestOptions struct {
-	SubmissionTimestampUnit string `json:"submission_timestamp_unit"`
+	SubmissionTimestampUnit     string   `json:"submission_timestamp_unit"`
+	RuleSet                     string   `json:"rule_set,omitempty"`                       // 计分规则：icpc、ccpc、oi、codeforces，默认根据比赛类型选择
+	ProblemScores               []int    `json:"problem_scores,omitempty"`                 // 每道题目的满分（OI、Codeforces 规则使用）
+	AllowTies                   bool     `json:"allow_ties,omitempty"`                     // ICPC 规则下解题数和罚时相同即并列，不再比较通过时间
+	PenaltyVerdicts             []string `json:"penalty_verdicts,omitempty"`               // 计入错误尝试的评测结果，未配置时使用计分规则的默认设置
+	FirstSolveIncludeUnofficial bool     `json:"first_solve_include_unofficial,omitempty"` // 打星队伍是否参与一血评选
 }
 
 // Team 表示一个参赛队伍
@@ -69,6 +74,16 @@ type Team struct {
 	IsVocational    bool `json:"vocational,omitempty"`
 }
 
+// IsOfficial 判断队伍是否为正式队伍（非打星队伍）
+func (t *Team) IsOfficial() bool {
+	for _, group := range t.Groups {
+		if group == "unofficial" {
+			return false
+		}
+	}
+	return true
+}
+
 // Run 表示一次提交记录
 type Run struct {
 	ID        string `json:"submission_id"`
@@ -77,6 +92,12 @@ type Run struct {
 	ProblemID int    `json:"problem_id"`
 	Timestamp int64  `json:"timestamp"`
 	Language  string `json:"language"`
+	Score     int    `json:"score,omitempty"` // 部分分（OI 规则使用）
+
+	// 相对比赛开始的提交时间，由 NormalizeRuns 根据时间戳单位换算
+	Time time.Duration `json:"-"`
+	// 统一写法后的评测结果，由 NormalizeRuns 根据 Status 解析
+	Verdict Verdict `json:"-"`
 }
 
 // GetStatus 获取比赛当前状态
@@ -100,6 +121,8 @@ func (c *Contest) GetTimeInfo() map[string]interface{} {
 		"start_time":     c.StartTime,
 		"end_time":       c.EndTime,
 		"frozen_time":    c.FrozenTime,
+		"unfreeze_time":  c.UnfreezeTime,
+		"is_frozen":      c.IsFrozenAt(now),
 		"current_time":   now,
 		"remaining_time": c.EndTime - now,
 		"elapsed_time":   now - c.StartTime,
@@ -121,23 +144,52 @@ func (c *Contest) GetTimeInfo() map[string]interface{} {
 type Result struct {
 	TeamID         string                    `json:"team_id"`
 	Team           *Team                     `json:"team"`
-	Rank           int                       `json:"rank"`
+	Rank           int                       `json:"rank"`          // 在榜单中的位置，包括打星队伍
+	OfficialRank   int                       `json:"official_rank"` // 正式排名，打星队伍为 0
 	Score          int                       `json:"score"`
 	TotalTime      int64                     `json:"total_time"`
+	SolvedTimes    []int64                   `json:"solved_times,omitempty"` // 各题通过时间（分钟），升序
 	ProblemResults map[string]*ProblemResult `json:"problem_results"`
 	SchoolRank     int                       `json:"school_rank"`
+	Medal          string                    `json:"medal,omitempty"`
+}
+
+// SolvedCount 获取队伍通过的题目数，按得分排名的规则下与 Score 不同
+func (r *Result) SolvedCount() int {
+	count := 0
+	for _, problemResult := range r.ProblemResults {
+		if problemResult.Solved {
+			count++
+		}
+	}
+	return count
 }
 
+// 奖牌类型，按颁发顺序排列
+const (
+	MedalGold   = "gold"
+	MedalSilver = "silver"
+	MedalBronze = "bronze"
+)
+
+// MedalTypes 奖牌类型列表（金、银、铜）
+var MedalTypes = []string{MedalGold, MedalSilver, MedalBronze}
+
 // ProblemResult 表示一个题目的结果
 type ProblemResult struct {
-	ProblemID       string `json:"problem_id"`
-	Attempts        int    `json:"attempts"`
-	Solved          bool   `json:"solved"`
-	SolvedTime      int64  `json:"solved_time,omitempty"`
-	PenaltyTime     int64  `json:"penalty_time,omitempty"`
-	FirstToSolve    bool   `json:"first_to_solve,omitempty"`
-	IsFrozen        bool   `json:"is_frozen,omitempty"`
-	PendingAttempts int    `json:"pending_attempts,omitempty"`
+	ProblemID          string `json:"problem_id"`
+	Attempts           int    `json:"attempts"`
+	Solved             bool   `json:"solved"`
+	Score              int    `json:"score,omitempty"`
+	SolvedTime         int64  `json:"solved_time,omitempty"`
+	PenaltyTime        int64  `json:"penalty_time,omitempty"`
+	FirstToSolve       bool   `json:"first_to_solve,omitempty"`        // 当前筛选条件下的首A
+	FirstToSolveGlobal bool   `json:"first_to_solve_global,omitempty"` // 全场一血
+	IsFrozen           bool   `json:"is_frozen,omitempty"`
+	PendingAttempts    int    `json:"pending_attempts,omitempty"`
+
+	// 精确的通过时间（相对比赛开始），用于判断一血
+	SolvedAt time.Duration `json:"-"`
 }
 
 // ContestDirectory 比赛目录结构
@@ -155,46 +207,32 @@ type ContestInfo struct {
 	Type         string `json:"type,omitempty"`
 }
 
-const dataDir = "data"
-
-// LoadContestConfig 只加载比赛的基本配置信息
-func LoadContestConfig(contestID string) (*Contest, error) {
-	contestDir := filepath.Join(dataDir, filepath.FromSlash(contestID))
-
-	// 加载配置
-	configPath := filepath.Join(contestDir, "config.json")
-	configData, err := os.ReadFile(configPath)
+// LoadContestConfig 从存储中加载比赛的基本配置信息和封榜状态
+func LoadContestConfig(store ContestStore, contestID string) (*Contest, error) {
+	contest, err := store.LoadConfig(contestID)
 	if err != nil {
-		return nil, fmt.Errorf("failed to read config.json: %w", err)
-	}
-
-	var contest Contest
-	if err := json.Unmarshal(configData, &contest); err != nil {
-		return nil, fmt.Errorf("failed to parse config.json: %w", err)
+		return nil, err
 	}
 
 	contest.ID = contestID
-	// 保存数据目录路径，用于后续按需加载
-	contest.dataDir = dataDir
+	// 保存所在的存储，用于后续按需加载
+	contest.store = store
 
-	return &contest, nil
+	// 加载封榜状态
+	if err := contest.loadFreezeState(); err != nil {
+		return nil, err
+	}
+
+	return contest, nil
 }
 
 // LoadAllContests 加载所有比赛的基本配置
-func LoadAllContests() (map[string]*Contest, error) {
+func LoadAllContests(store ContestStore) (map[string]*Contest, error) {
 	contestsMap := make(map[string]*Contest)
 
-	// 尝试从目录文件加载比赛列表
-	dirPath := filepath.Join(dataDir, "directory.json")
-	dirData, err := os.ReadFile(dirPath)
-
-	// 如果目录文件存在，直接从目录加载基本信息，然后按需加载详细配置
+	// 如果目录存在，直接从目录加载基本信息，然后按需加载详细配置
+	directory, err := store.LoadDirectory()
 	if err == nil {
-		var directory ContestDirectory
-		if err := json.Unmarshal(dirData, &directory); err != nil {
-			return nil, fmt.Errorf("failed to parse directory.json: %w", err)
-		}
-
 		// 直接使用目录中的基本信息创建Contest对象
 		for contestID, contestInfo := range directory.Contests {
 			contest := &Contest{
@@ -204,66 +242,41 @@ func LoadAllContests() (map[string]*Contest, error) {
 				EndTime:      contestInfo.EndTime,
 				Organization: contestInfo.Organization,
 				Type:         contestInfo.Type,
-				dataDir:      dataDir,
+				store:        store,
 			}
 			contestsMap[contestID] = contest
 		}
 
 		return contestsMap, nil
 	}
+	if !errors.Is(err, ErrNotExist) {
+		return nil, err
+	}
+
+	// 如果目录不存在，回退到旧方法:扫描所有比赛
+	contestIDs, err := store.ListContests()
+	if err != nil {
+		return nil, err
+	}
 
-	// 如果目录文件不存在，回退到旧方法:递归扫描数据目录
 	contestInfoMap := make(map[string]ContestInfo)
-	err = filepath.Walk(dataDir, func(path string, info os.FileInfo, err error) error {
+	for _, contestID := range contestIDs {
+		// 加载比赛基本配置
+		contest, err := LoadContestConfig(store, contestID)
 		if err != nil {
-			return err
-		}
-
-		// 检查是否是config.json文件
-		if !info.IsDir() && info.Name() == "config.json" {
-			// 获取比赛ID (基于目录路径)
-			relPath, err := filepath.Rel(dataDir, filepath.Dir(path))
-			if err != nil {
-				return err
-			}
-
-			contestID := strings.Replace(relPath, string(filepath.Separator), "/", -1)
-
-			// 加载比赛基本配置
-			contest, err := LoadContestConfig(contestID)
-			if err != nil {
-				return err
-			}
-
-			// 保存到结果map
-			contestsMap[contestID] = contest
-
-			// 为目录文件只准备首页所需的数据
-			contestType := "Other" // 默认类型
-			if strings.Contains(strings.ToLower(contest.Name), "provincial") {
-				contestType = "Provincial"
-			}
-
-			contestInfoMap[contestID] = ContestInfo{
-				ID:           contest.ID,
-				Name:         contest.Name,
-				StartTime:    contest.StartTime,
-				EndTime:      contest.EndTime,
-				Organization: contest.Organization,
-				Type:         contestType,
-			}
+			return nil, err
 		}
 
-		return nil
-	})
+		// 保存到结果map
+		contestsMap[contestID] = contest
 
-	if err != nil {
-		return nil, err
+		// 为目录只准备首页所需的数据
+		contestInfoMap[contestID] = newContestInfo(contest)
 	}
 
-	// 创建目录文件以便将来使用
+	// 创建目录以便将来使用
 	if len(contestInfoMap) > 0 {
-		if err := UpdateContestDirectory(contestInfoMap); err != nil {
+		if err := UpdateContestDirectory(store, contestInfoMap); err != nil {
 			// 只记录错误，不影响返回结果
 			fmt.Printf("Warning: failed to create contest directory: %v\n", err)
 		}
@@ -272,52 +285,92 @@ func LoadAllContests() (map[string]*Contest, error) {
 	return contestsMap, nil
 }
 
-// UpdateContestDirectory 更新比赛目录文件
-func UpdateContestDirectory(contestInfoMap map[string]ContestInfo) error {
-	directory := ContestDirectory{
-		Contests: contestInfoMap,
-	}
+// UpdateContestDirectory 把比赛信息合并到比赛目录中，目录中已有的其他比赛保持不变
+func UpdateContestDirectory(store ContestStore, contestInfoMap map[string]ContestInfo) error {
+	return store.UpdateDirectory(func(directory *ContestDirectory) error {
+		for contestID, info := range contestInfoMap {
+			directory.Contests[contestID] = info
+		}
+		return nil
+	})
+}
 
-	dirData, err := json.MarshalIndent(directory, "", "  ")
-	if err != nil {
-		return fmt.Errorf("failed to marshal directory data: %w", err)
-	}
+// AddContestToDirectory 向目录添加新比赛
+func AddContestToDirectory(store ContestStore, contest *Contest) error {
+	return UpdateContestDirectory(store, map[string]ContestInfo{
+		contest.ID: newContestInfo(contest),
+	})
+}
+
+// DirectoryChanges 重建比赛目录时发生的变化
+type DirectoryChanges struct {
+	Added   []string `json:"added"`   // 目录中缺失的比赛
+	Updated []string `json:"updated"` // 目录中信息与配置不一致的比赛
+	Removed []string `json:"removed"` // 目录中已不存在的比赛
+	Skipped []string `json:"skipped"` // 配置无法读取的比赛，保留目录中原有的信息
+}
 
-	dirPath := filepath.Join(dataDir, "directory.json")
-	if err := os.WriteFile(dirPath, dirData, 0644); err != nil {
-		return fmt.Errorf("failed to write directory.json: %w", err)
+// RebuildContestDirectory 重新扫描存储中的所有比赛，与比赛目录核对：
+// 补充缺失的比赛，更新信息过时的比赛，删除已不存在的比赛
+func RebuildContestDirectory(store ContestStore) (*DirectoryChanges, error) {
+	contestIDs, err := store.ListContests()
+	if err != nil {
+		return nil, err
 	}
 
-	return nil
-}
+	changes := &DirectoryChanges{}
+	found := make(map[string]bool, len(contestIDs))
+	infos := make(map[string]ContestInfo, len(contestIDs))
+	for _, contestID := range contestIDs {
+		found[contestID] = true
 
-// AddContestToDirectory 向目录添加新比赛
-func AddContestToDirectory(contest *Contest) error {
-	// 尝试加载现有的目录
-	dirPath := "data/directory.json"
-	var directory ContestDirectory
+		contest, err := LoadContestConfig(store, contestID)
+		if err != nil {
+			changes.Skipped = append(changes.Skipped, contestID)
+			continue
+		}
+		infos[contestID] = newContestInfo(contest)
+	}
 
-	dirData, err := os.ReadFile(dirPath)
-	if err == nil {
-		// 目录文件存在，解析现有数据
-		if err := json.Unmarshal(dirData, &directory); err != nil {
-			return fmt.Errorf("failed to parse directory.json: %w", err)
+	err = store.UpdateDirectory(func(directory *ContestDirectory) error {
+		for contestID, info := range infos {
+			old, ok := directory.Contests[contestID]
+			if !ok {
+				changes.Added = append(changes.Added, contestID)
+			} else if old != info {
+				changes.Updated = append(changes.Updated, contestID)
+			}
+			directory.Contests[contestID] = info
 		}
-	} else {
-		// 目录不存在，创建新的
-		directory = ContestDirectory{
-			Contests: make(map[string]ContestInfo),
+
+		for contestID := range directory.Contests {
+			if !found[contestID] {
+				changes.Removed = append(changes.Removed, contestID)
+				delete(directory.Contests, contestID)
+			}
 		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
 	}
 
+	sort.Strings(changes.Added)
+	sort.Strings(changes.Updated)
+	sort.Strings(changes.Removed)
+	sort.Strings(changes.Skipped)
+	return changes, nil
+}
+
+// newContestInfo 生成比赛在目录中的基本信息
+func newContestInfo(contest *Contest) ContestInfo {
 	// 确定比赛类型
 	contestType := "Other" // 默认类型
 	if strings.Contains(strings.ToLower(contest.Name), "provincial") {
 		contestType = "Provincial"
 	}
 
-	// 添加或更新比赛信息
-	directory.Contests[contest.ID] = ContestInfo{
+	return ContestInfo{
 		ID:           contest.ID,
 		Name:         contest.Name,
 		StartTime:    contest.StartTime,
@@ -325,182 +378,30 @@ func AddContestToDirectory(contest *Contest) error {
 		Organization: contest.Organization,
 		Type:         contestType,
 	}
-
-	// 写回目录文件
-	newDirData, err := json.MarshalIndent(directory, "", "  ")
-	if err != nil {
-		return fmt.Errorf("failed to marshal directory data: %w", err)
-	}
-
-	if err := os.WriteFile(dirPath, newDirData, 0644); err != nil {
-		return fmt.Errorf("failed to write directory.json: %w", err)
-	}
-
-	return nil
 }
 
 // LoadTeams 按需加载队伍数据
 func (c *Contest) LoadTeams() (map[string]*Team, error) {
-	if c.dataDir == "" {
-		return nil, fmt.Errorf("dataDir not set, cannot load teams")
+	if c.store == nil {
+		return nil, fmt.Errorf("store not set, cannot load teams")
 	}
 
-	contestDir := filepath.Join(c.dataDir, filepath.FromSlash(c.ID))
-
-	// 加载队伍数据
-	teamPath := filepath.Join(contestDir, "team.json")
-	teamData, err := os.ReadFile(teamPath)
-	if err != nil {
-		return nil, fmt.Errorf("failed to read team.json: %w", err)
-	}
-
-	var teamsMap map[string]*Team
-	if err := json.Unmarshal(teamData, &teamsMap); err != nil {
-		return nil, fmt.Errorf("failed to parse team.json: %w", err)
-	}
-
-	return teamsMap, nil
+	return c.store.LoadTeams(c.ID)
 }
 
 // LoadRuns 按需加载提交记录
 func (c *Contest) LoadRuns() ([]*Run, error) {
-	if c.dataDir == "" {
-		return nil, fmt.Errorf("dataDir not set, cannot load runs")
+	if c.store == nil {
+		return nil, fmt.Errorf("store not set, cannot load runs")
 	}
 
-	contestDir := filepath.Join(c.dataDir, filepath.FromSlash(c.ID))
-
-	// 加载提交记录
-	runPath := filepath.Join(contestDir, "run.json")
-	runData, err := os.ReadFile(runPath)
+	runs, err := c.store.LoadRuns(c.ID)
 	if err != nil {
-		return nil, fmt.Errorf("failed to read run.json: %w", err)
+		return nil, err
 	}
 
-	var runs []*Run
-	if err := json.Unmarshal(runData, &runs); err != nil {
-		return nil, fmt.Errorf("failed to parse run.json: %w", err)
-	}
+	// 统一时间戳单位
+	c.NormalizeRuns(runs)
 
 	return runs, nil
 }
-
-// CalculateResults 计算比赛结果
-func (c *Contest) CalculateResults() ([]*Result, error) {
-	// 按需加载队伍数据
-	teams, err := c.LoadTeams()
-	if err != nil {
-		return nil, fmt.Errorf("failed to load teams: %w", err)
-	}
-
-	// 按需加载提交记录
-	runs, err := c.LoadRuns()
-	if err != nil {
-		return nil, fmt.Errorf("failed to load runs: %w", err)
-	}
-
-	// 创建结果映射
-	resultsMap := make(map[string]*Result)
-
-	// 初始化结果
-	for teamID, team := range teams {
-		result := &Result{
-			TeamID:         teamID,
-			Team:           team,
-			ProblemResults: make(map[string]*ProblemResult),
-		}
-
-		// 初始化每个题目的结果
-		for _, problemID := range c.ProblemIDs {
-			result.ProblemResults[problemID] = &ProblemResult{
-				ProblemID: problemID,
-			}
-		}
-
-		resultsMap[teamID] = result
-	}
-
-	// 处理提交记录
-	for _, run := range runs {
-		// 跳过jury提交
-		if run.TeamID == "jury" {
-			continue
-		}
-
-		// 获取队伍结果
-		result, ok := resultsMap[run.TeamID]
-		if !ok {
-			continue
-		}
-
-		// 查找对应题目ID
-		if run.ProblemID >= len(c.ProblemIDs) {
-			continue
-		}
-		problemID := c.ProblemIDs[run.ProblemID]
-
-		// 获取题目结果
-		problemResult, ok := result.ProblemResults[problemID]
-		if !ok {
-			continue
-		}
-
-		// 如果题目已解决，跳过后续提交
-		if problemResult.Solved {
-			continue
-		}
-
-		// 检查是否在封榜时间内
-		// 结合当前时间是否在封榜时间内
-		var isFrozen bool
-		if now := time.Now().Unix(); now >= c.EndTime-c.FrozenTime && now <= c.EndTime {
-			// 比赛总时长减去提交的相对时间戳（毫秒转换为秒），如果小于等于封榜时间，则在封榜范围内
-			isFrozen = (c.EndTime-c.StartTime)-run.Timestamp/1000 <= c.FrozenTime
-		}
-
-		// 根据状态处理
-		switch run.Status {
-		case "ACCEPTED":
-			if isFrozen {
-				problemResult.IsFrozen = true
-				problemResult.PendingAttempts++
-			} else {
-				problemResult.Solved = true
-				// run.Timestamp 是毫秒级的相对时间戳，需要转换为秒，再转换为分钟
-				problemResult.SolvedTime = (run.Timestamp / 1000) / 60
-				// 计算罚时：解题时间(分钟)加上之前错误尝试的罚时(分钟)
-				problemResult.PenaltyTime = problemResult.SolvedTime + int64(problemResult.Attempts)*(c.Penalty/60)
-
-				// 更新总分和时间（以分钟为单位）
-				result.Score++
-				result.TotalTime += problemResult.PenaltyTime
-			}
-		case "WRONG_ANSWER", "TIME_LIMIT_EXCEEDED", "RUNTIME_ERROR", "COMPILATION_ERROR":
-			if isFrozen {
-				problemResult.IsFrozen = true
-				problemResult.PendingAttempts++
-			} else {
-				problemResult.Attempts++
-			}
-		}
-	}
-
-	// 将结果映射转换为列表
-	var resultsList []*Result
-	for _, result := range resultsMap {
-		resultsList = append(resultsList, result)
-	}
-
-	return resultsList, nil
-}
-
-// GetVisibleResults 获取当前可见的结果（考虑封榜），不计算排名
-func (c *Contest) GetVisibleResults() ([]*Result, error) {
-	// 计算结果
-	results, err := c.CalculateResults()
-	if err != nil {
-		return nil, err
-	}
-
-	return results, nil
-}
@@ -0,0 +1,22 @@
+package model_test
+
+import (
+	"testing"
+
+	"github.com/lllllan02/scoreboard/internal/model"
+)
+
+func TestResultSolvedCount(t *testing.T) {
+	// 按得分排名时，部分分和通过题目的分值都不等于解题数
+	result := &model.Result{
+		Score: 60 + 100,
+		ProblemResults: map[string]*model.ProblemResult{
+			"A": {ProblemID: "A", Score: 60},
+			"B": {ProblemID: "B", Score: 100, Solved: true},
+			"C": {ProblemID: "C"},
+		},
+	}
+	if got := result.SolvedCount(); got != 1 {
+		t.Errorf("SolvedCount() = %d, want 1", got)
+	}
+}
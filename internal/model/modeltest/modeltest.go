@@ -0,0 +1,32 @@
+package modeltest
+
+import "github.com/lllllan02/scoreboard/internal/model"
+
+// NewContest 创建三道题、时长 5 小时、罚时 20 分钟、不封榜的比赛，提交时间戳以秒为单位
+func NewContest() *model.Contest {
+	return &model.Contest{
+		ID:           "test",
+		Name:         "Test Contest",
+		StartTime:    1700000000,
+		EndTime:      1700000000 + 5*3600,
+		Penalty:      20 * 60,
+		ProblemCount: 3,
+		ProblemIDs:   []string{"A", "B", "C"},
+		Groups:       map[string]string{"official": "正式队伍"},
+		Options:      model.ContestOptions{SubmissionTimestampUnit: model.TimestampUnitSecond},
+	}
+}
+
+// NewTeams 创建指定编号的队伍
+func NewTeams(teamIDs ...string) map[string]*model.Team {
+	teams := make(map[string]*model.Team, len(teamIDs))
+	for _, teamID := range teamIDs {
+		teams[teamID] = &model.Team{ID: teamID, Name: "Team " + teamID, Organization: "School " + teamID}
+	}
+	return teams
+}
+
+// RunAt 创建队伍在比赛开始 minute 分钟时对题目的提交，时间戳以秒为单位
+func RunAt(id, teamID string, problem int, minute int64, status string) *model.Run {
+	return &model.Run{ID: id, TeamID: teamID, ProblemID: problem, Timestamp: minute * 60, Status: status}
+}
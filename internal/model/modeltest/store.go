@@ -0,0 +1,214 @@
+package modeltest
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+	"sync"
+	"testing"
+
+	"github.com/lllllan02/scoreboard/internal/model"
+)
+
+// ImportContest 将比赛配置、队伍和提交记录以 xcpcio 格式保存到存储中
+func ImportContest(t testing.TB, store model.ContestStore, contest *model.Contest, teams map[string]*model.Team, runs []*model.Run) {
+	t.Helper()
+
+	config, err := json.Marshal(contest)
+	if err != nil {
+		t.Fatal(err)
+	}
+	teamData, err := json.Marshal(teams)
+	if err != nil {
+		t.Fatal(err)
+	}
+	runData, err := json.Marshal(runs)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := store.ImportContest(contest.ID, config, teamData, runData); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// TestContestStore 检查空存储的读写行为是否符合 model.ContestStore 的约定
+func TestContestStore(t *testing.T, store model.ContestStore) {
+	contest := NewContest()
+	contest.ID = "test/store"
+	teams := NewTeams("t1", "t2")
+	runs := []*model.Run{
+		RunAt("1", "t1", 0, 10, "WRONG_ANSWER"),
+		RunAt("2", "t2", 1, 20, "ACCEPTED"),
+	}
+
+	t.Run("empty", func(t *testing.T) {
+		contestIDs, err := store.ListContests()
+		if err != nil || len(contestIDs) != 0 {
+			t.Errorf("ListContests() = %v, %v, want no contests", contestIDs, err)
+		}
+		if _, err := store.LoadDirectory(); !errors.Is(err, model.ErrNotExist) {
+			t.Errorf("LoadDirectory() error = %v, want ErrNotExist", err)
+		}
+		if _, err := store.LoadConfig(contest.ID); !errors.Is(err, model.ErrNotExist) {
+			t.Errorf("LoadConfig() error = %v, want ErrNotExist", err)
+		}
+		if _, err := store.Stamp(contest.ID); !errors.Is(err, model.ErrNotExist) {
+			t.Errorf("Stamp() error = %v, want ErrNotExist", err)
+		}
+	})
+
+	ImportContest(t, store, contest, teams, runs)
+
+	t.Run("import", func(t *testing.T) {
+		contestIDs, err := store.ListContests()
+		if err != nil || !reflect.DeepEqual(contestIDs, []string{contest.ID}) {
+			t.Errorf("ListContests() = %v, %v, want [%s]", contestIDs, err, contest.ID)
+		}
+
+		config, err := store.LoadConfig(contest.ID)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if config.Name != contest.Name || config.EndTime != contest.EndTime || !reflect.DeepEqual(config.ProblemIDs, contest.ProblemIDs) {
+			t.Errorf("LoadConfig() = %+v, want %+v", config, contest)
+		}
+
+		gotTeams, err := store.LoadTeams(contest.ID)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !reflect.DeepEqual(gotTeams, teams) {
+			t.Errorf("LoadTeams() = %v, want %v", gotTeams, teams)
+		}
+
+		gotRuns, err := store.LoadRuns(contest.ID)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !reflect.DeepEqual(gotRuns, runs) {
+			t.Errorf("LoadRuns() = %v, want %v", gotRuns, runs)
+		}
+	})
+
+	t.Run("raw", func(t *testing.T) {
+		config, teamData, runData, err := store.LoadRaw(contest.ID)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		var gotConfig model.Contest
+		var gotTeams map[string]*model.Team
+		var gotRuns []*model.Run
+		if err := json.Unmarshal(config, &gotConfig); err != nil || gotConfig.Name != contest.Name {
+			t.Errorf("LoadRaw() config = %s, %v, want the imported config", config, err)
+		}
+		if err := json.Unmarshal(teamData, &gotTeams); err != nil || !reflect.DeepEqual(gotTeams, teams) {
+			t.Errorf("LoadRaw() teams = %s, %v, want %v", teamData, err, teams)
+		}
+		if err := json.Unmarshal(runData, &gotRuns); err != nil || !reflect.DeepEqual(gotRuns, runs) {
+			t.Errorf("LoadRaw() runs = %s, %v, want %v", runData, err, runs)
+		}
+	})
+
+	t.Run("stamp", func(t *testing.T) {
+		before, err := store.Stamp(contest.ID)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if again, err := store.Stamp(contest.ID); err != nil || again != before {
+			t.Errorf("Stamp() changed without writes: %+v, want %+v", again, before)
+		}
+
+		ImportContest(t, store, contest, teams, append(runs[:2:2], RunAt("3", "t1", 0, 30, "ACCEPTED")))
+		after, err := store.Stamp(contest.ID)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if after.Runs == before.Runs {
+			t.Errorf("Stamp().Runs = %q after the runs changed, want a new stamp", after.Runs)
+		}
+	})
+
+	t.Run("freeze state", func(t *testing.T) {
+		state, err := store.LoadFreezeState(contest.ID)
+		if err != nil || state != nil {
+			t.Fatalf("LoadFreezeState() = %v, %v before saving, want nil", state, err)
+		}
+
+		before, err := store.Stamp(contest.ID)
+		if err != nil {
+			t.Fatal(err)
+		}
+		want := &model.FreezeState{UnfreezeTime: contest.EndTime + 600}
+		if err := store.SaveFreezeState(contest.ID, want); err != nil {
+			t.Fatal(err)
+		}
+		if state, err := store.LoadFreezeState(contest.ID); err != nil || !reflect.DeepEqual(state, want) {
+			t.Errorf("LoadFreezeState() = %v, %v, want %v", state, err, want)
+		}
+		if after, err := store.Stamp(contest.ID); err != nil || after.Config == before.Config {
+			t.Errorf("Stamp().Config = %q after the freeze state changed, want a new stamp", after.Config)
+		}
+	})
+
+	t.Run("directory", func(t *testing.T) {
+		want := &model.ContestDirectory{Contests: map[string]model.ContestInfo{
+			contest.ID: {ID: contest.ID, Name: contest.Name, StartTime: contest.StartTime, EndTime: contest.EndTime},
+		}}
+		err := store.UpdateDirectory(func(directory *model.ContestDirectory) error {
+			if len(directory.Contests) != 0 {
+				t.Errorf("UpdateDirectory() started from %v, want an empty directory", directory.Contests)
+			}
+			directory.Contests[contest.ID] = want.Contests[contest.ID]
+			return nil
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if directory, err := store.LoadDirectory(); err != nil || !reflect.DeepEqual(directory, want) {
+			t.Errorf("LoadDirectory() = %v, %v, want %v", directory, err, want)
+		}
+
+		// update 返回错误时不保存
+		errUpdate := errors.New("update failed")
+		err = store.UpdateDirectory(func(directory *model.ContestDirectory) error {
+			delete(directory.Contests, contest.ID)
+			return errUpdate
+		})
+		if !errors.Is(err, errUpdate) {
+			t.Errorf("UpdateDirectory() error = %v, want %v", err, errUpdate)
+		}
+		if directory, err := store.LoadDirectory(); err != nil || !reflect.DeepEqual(directory, want) {
+			t.Errorf("LoadDirectory() = %v, %v after a failed update, want %v", directory, err, want)
+		}
+	})
+
+	t.Run("concurrent directory updates", func(t *testing.T) {
+		var wg sync.WaitGroup
+		for i := 0; i < 10; i++ {
+			wg.Add(1)
+			go func(i int) {
+				defer wg.Done()
+				contestID := fmt.Sprintf("test/concurrent-%d", i)
+				err := store.UpdateDirectory(func(directory *model.ContestDirectory) error {
+					directory.Contests[contestID] = model.ContestInfo{ID: contestID}
+					return nil
+				})
+				if err != nil {
+					t.Error(err)
+				}
+			}(i)
+		}
+		wg.Wait()
+
+		// 每次更新都基于上一次的结果，不会丢失其他更新写入的比赛
+		directory, err := store.LoadDirectory()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(directory.Contests) != 11 {
+			t.Errorf("directory has %d contests after concurrent updates, want 11", len(directory.Contests))
+		}
+	})
+}
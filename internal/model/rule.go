@@ -0,0 +1,174 @@
+package model
+
+import "strings"
+
+// RuleSet 定义比赛的计分规则，包括题目得分、罚时、排名比较以及哪些提交计入错误尝试
+type RuleSet interface {
+	// Name 返回规则名称
+	Name() string
+
+	// IsPenalized 判断该评测结果是否默认计为一次错误尝试
+	IsPenalized(v Verdict) bool
+
+	// ProblemScore 计算一次提交为题目带来的得分，题目最终得分取所有提交中的最高值
+	// index 为题目下标，pr 为该提交之前的题目结果
+	ProblemScore(c *Contest, index int, pr *ProblemResult, run *Run) int
+
+	// ProblemPenalty 计算已通过题目的罚时（分钟）
+	ProblemPenalty(c *Contest, pr *ProblemResult) int64
+
+	// Compare 比较两个结果的排名先后，负数表示 a 排在 b 前面，0 表示并列
+	Compare(a, b *Result) int
+}
+
+// 默认使用的规则名称
+const DefaultRuleSet = "icpc"
+
+// ruleSets 已注册的计分规则，根据比赛配置创建规则实例
+var ruleSets = map[string]func(c *Contest) RuleSet{
+	"icpc":       func(c *Contest) RuleSet { return icpcRule{allowTies: c.Options.AllowTies} },
+	"ccpc":       func(c *Contest) RuleSet { return ccpcRule{icpcRule{allowTies: c.Options.AllowTies}} },
+	"oi":         func(c *Contest) RuleSet { return oiRule{} },
+	"ioi":        func(c *Contest) RuleSet { return oiRule{} },
+	"codeforces": func(c *Contest) RuleSet { return codeforcesRule{} },
+	"cf":         func(c *Contest) RuleSet { return codeforcesRule{} },
+}
+
+// RuleSet 获取比赛使用的计分规则
+// 优先使用 options.rule_set，其次根据比赛类型选择，默认使用 ICPC 规则
+func (c *Contest) RuleSet() RuleSet {
+	for _, name := range []string{c.Options.RuleSet, c.Type} {
+		if newRuleSet, ok := ruleSets[strings.ToLower(name)]; ok {
+			return newRuleSet(c)
+		}
+	}
+	return ruleSets[DefaultRuleSet](c)
+}
+
+// ProblemMaxScore 获取题目的满分，未配置时使用 defaultScore
+func (c *Contest) ProblemMaxScore(index int, defaultScore int) int {
+	if index >= 0 && index < len(c.Options.ProblemScores) && c.Options.ProblemScores[index] > 0 {
+		return c.Options.ProblemScores[index]
+	}
+	return defaultScore
+}
+
+// icpcRule ICPC 规则：通过即得 1 分，罚时为通过时间加上错误尝试次数乘以罚时，
+// 按解题数降序、总罚时升序排名，仍然相同时依次比较最后一题、倒数第二题……的通过时间
+type icpcRule struct {
+	// 为 true 时不比较通过时间，解题数和罚时相同即并列
+	allowTies bool
+}
+
+func (icpcRule) Name() string { return "icpc" }
+
+func (icpcRule) IsPenalized(v Verdict) bool {
+	return v.IsRejected()
+}
+
+func (icpcRule) ProblemScore(c *Contest, index int, pr *ProblemResult, run *Run) int {
+	if run.Verdict.IsAccepted() {
+		return 1
+	}
+	return 0
+}
+
+func (icpcRule) ProblemPenalty(c *Contest, pr *ProblemResult) int64 {
+	// 解题时间(分钟)加上之前错误尝试的罚时(分钟)
+	return pr.SolvedTime + int64(pr.Attempts)*(c.Penalty/60)
+}
+
+func (r icpcRule) Compare(a, b *Result) int {
+	// 首先按解题数量排序（降序）
+	if a.Score != b.Score {
+		return b.Score - a.Score
+	}
+	// 如果解题数量相同，按罚时排序（升序）
+	if cmp := compareInt64(a.TotalTime, b.TotalTime); cmp != 0 || r.allowTies {
+		return cmp
+	}
+	// 如果罚时也相同，最后一题通过时间早的队伍排在前面，依此类推
+	for i, j := len(a.SolvedTimes)-1, len(b.SolvedTimes)-1; i >= 0 && j >= 0; i, j = i-1, j-1 {
+		if cmp := compareInt64(a.SolvedTimes[i], b.SolvedTimes[j]); cmp != 0 {
+			return cmp
+		}
+	}
+	return 0
+}
+
+// ccpcRule CCPC 规则：与 ICPC 规则相同，但编译错误不计入错误尝试
+type ccpcRule struct {
+	icpcRule
+}
+
+func (ccpcRule) Name() string { return "ccpc" }
+
+func (ccpcRule) IsPenalized(v Verdict) bool {
+	return v.IsRejected() && v != VerdictCompilationError
+}
+
+// oiRule OI/IOI 规则：每题取最高得分，没有罚时，按总分降序排名
+// 提交记录带有 score 时按部分分计算，否则通过即得满分（默认 100 分）
+type oiRule struct{}
+
+func (oiRule) Name() string { return "oi" }
+
+func (oiRule) IsPenalized(v Verdict) bool {
+	return v.IsRejected()
+}
+
+func (oiRule) ProblemScore(c *Contest, index int, pr *ProblemResult, run *Run) int {
+	maxScore := c.ProblemMaxScore(index, 100)
+	if run.Verdict.IsAccepted() {
+		return maxScore
+	}
+	return min(run.Score, maxScore)
+}
+
+func (oiRule) ProblemPenalty(c *Contest, pr *ProblemResult) int64 {
+	return 0
+}
+
+func (oiRule) Compare(a, b *Result) int {
+	return b.Score - a.Score
+}
+
+// codeforcesRule Codeforces 规则：题目分值随通过时间递减，每次错误尝试扣 50 分，
+// 最低保留满分的 30%，按总分降序排名。未配置分值时第 i 题满分为 500*(i+1)
+type codeforcesRule struct{}
+
+func (codeforcesRule) Name() string { return "codeforces" }
+
+func (codeforcesRule) IsPenalized(v Verdict) bool {
+	// 编译错误不扣分
+	return v.IsRejected() && v != VerdictCompilationError
+}
+
+func (codeforcesRule) ProblemScore(c *Contest, index int, pr *ProblemResult, run *Run) int {
+	if !run.Verdict.IsAccepted() {
+		return 0
+	}
+	maxScore := c.ProblemMaxScore(index, 500*(index+1))
+	minute := int(run.Minute())
+	score := maxScore - maxScore*minute/250 - 50*pr.Attempts
+	return max(score, maxScore*3/10)
+}
+
+func (codeforcesRule) ProblemPenalty(c *Contest, pr *ProblemResult) int64 {
+	return 0
+}
+
+func (codeforcesRule) Compare(a, b *Result) int {
+	return b.Score - a.Score
+}
+
+// compareInt64 比较两个整数，返回 -1、0、1
+func compareInt64(a, b int64) int {
+	switch {
+	case a < b:
+		return -1
+	case a > b:
+		return 1
+	}
+	return 0
+}
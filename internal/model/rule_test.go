@@ -0,0 +1,252 @@
+package model_test
+
+import (
+	"testing"
+
+	"github.com/lllllan02/scoreboard/internal/model"
+	"github.com/lllllan02/scoreboard/internal/model/modeltest"
+)
+
+func TestRuleSet(t *testing.T) {
+	tests := []struct {
+		contestType string
+		ruleSet     string
+		want        string
+	}{
+		{"", "", "icpc"},
+		{"icpc", "", "icpc"},
+		{"ccpc", "", "ccpc"},
+		{"", "ccpc", "ccpc"},
+		{"ioi", "", "oi"},
+		{"", "OI", "oi"},
+		{"cf", "", "codeforces"},
+		{"icpc", "codeforces", "codeforces"},
+		{"provincial", "", "icpc"},
+		{"ccpc", "unknown", "ccpc"},
+	}
+
+	for _, tt := range tests {
+		c := modeltest.NewContest()
+		c.Type = tt.contestType
+		c.Options.RuleSet = tt.ruleSet
+		if got := c.RuleSet().Name(); got != tt.want {
+			t.Errorf("RuleSet() with type %q and rule_set %q = %s, want %s", tt.contestType, tt.ruleSet, got, tt.want)
+		}
+	}
+}
+
+func TestRuleIsPenalized(t *testing.T) {
+	tests := []struct {
+		ruleSet string
+		verdict model.Verdict
+		want    bool
+	}{
+		{"icpc", model.VerdictWrongAnswer, true},
+		{"icpc", model.VerdictCompilationError, true},
+		{"icpc", model.VerdictAccepted, false},
+		{"icpc", model.VerdictPending, false},
+		{"icpc", model.VerdictJudgementFailed, false},
+		{"ccpc", model.VerdictTimeLimitExceeded, true},
+		{"ccpc", model.VerdictCompilationError, false},
+		{"oi", model.VerdictRuntimeError, true},
+		{"codeforces", model.VerdictWrongAnswer, true},
+		{"codeforces", model.VerdictCompilationError, false},
+	}
+
+	for _, tt := range tests {
+		c := modeltest.NewContest()
+		c.Options.RuleSet = tt.ruleSet
+		if got := c.RuleSet().IsPenalized(tt.verdict); got != tt.want {
+			t.Errorf("%s IsPenalized(%s) = %v, want %v", tt.ruleSet, tt.verdict, got, tt.want)
+		}
+	}
+}
+
+func TestRuleProblemScore(t *testing.T) {
+	tests := []struct {
+		name          string
+		ruleSet       string
+		problemScores []int
+		attempts      int
+		run           *model.Run
+		want          int
+	}{
+		{
+			name:    "icpc accepted",
+			ruleSet: "icpc",
+			run:     modeltest.RunAt("1", "t1", 0, 10, "ACCEPTED"),
+			want:    1,
+		},
+		{
+			name:    "icpc rejected",
+			ruleSet: "icpc",
+			run:     modeltest.RunAt("1", "t1", 0, 10, "WRONG_ANSWER"),
+			want:    0,
+		},
+		{
+			name:    "oi partial score",
+			ruleSet: "oi",
+			run:     &model.Run{ID: "1", TeamID: "t1", ProblemID: 0, Status: "WRONG_ANSWER", Score: 60},
+			want:    60,
+		},
+		{
+			name:    "oi accepted gets the full score",
+			ruleSet: "oi",
+			run:     modeltest.RunAt("1", "t1", 0, 10, "ACCEPTED"),
+			want:    100,
+		},
+		{
+			name:          "oi partial score is capped by the configured score",
+			ruleSet:       "oi",
+			problemScores: []int{50, 80, 0},
+			run:           &model.Run{ID: "1", TeamID: "t1", ProblemID: 1, Status: "WRONG_ANSWER", Score: 200},
+			want:          80,
+		},
+		{
+			name:     "codeforces decays with time and attempts",
+			ruleSet:  "codeforces",
+			attempts: 1,
+			run:      modeltest.RunAt("1", "t1", 0, 25, "ACCEPTED"),
+			want:     500 - 50 - 50,
+		},
+		{
+			name:    "codeforces default score grows with the problem index",
+			ruleSet: "codeforces",
+			run:     modeltest.RunAt("1", "t1", 2, 100, "ACCEPTED"),
+			want:    1500 - 600,
+		},
+		{
+			name:    "codeforces keeps thirty percent",
+			ruleSet: "codeforces",
+			run:     modeltest.RunAt("1", "t1", 0, 240, "ACCEPTED"),
+			want:    150,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := modeltest.NewContest()
+			c.Options.RuleSet = tt.ruleSet
+			c.Options.ProblemScores = tt.problemScores
+			c.NormalizeRuns([]*model.Run{tt.run})
+
+			pr := &model.ProblemResult{ProblemID: c.ProblemIDs[tt.run.ProblemID], Attempts: tt.attempts}
+			if got := c.RuleSet().ProblemScore(c, tt.run.ProblemID, pr, tt.run); got != tt.want {
+				t.Errorf("ProblemScore() = %d, want %d", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRuleProblemPenalty(t *testing.T) {
+	pr := &model.ProblemResult{ProblemID: "A", Attempts: 2, Solved: true, SolvedTime: 30}
+
+	tests := []struct {
+		ruleSet string
+		want    int64
+	}{
+		{"icpc", 30 + 2*20},
+		{"ccpc", 30 + 2*20},
+		{"oi", 0},
+		{"codeforces", 0},
+	}
+
+	for _, tt := range tests {
+		c := modeltest.NewContest()
+		c.Options.RuleSet = tt.ruleSet
+		if got := c.RuleSet().ProblemPenalty(c, pr); got != tt.want {
+			t.Errorf("%s ProblemPenalty() = %d, want %d", tt.ruleSet, got, tt.want)
+		}
+	}
+}
+
+func TestRuleCompare(t *testing.T) {
+	tests := []struct {
+		name      string
+		ruleSet   string
+		allowTies bool
+		a, b      *model.Result
+		want      int // 比较结果的符号
+	}{
+		{
+			name:    "more solved first",
+			ruleSet: "icpc",
+			a:       &model.Result{Score: 3, TotalTime: 500},
+			b:       &model.Result{Score: 2, TotalTime: 100},
+			want:    -1,
+		},
+		{
+			name:    "less penalty first",
+			ruleSet: "icpc",
+			a:       &model.Result{Score: 2, TotalTime: 120},
+			b:       &model.Result{Score: 2, TotalTime: 100},
+			want:    1,
+		},
+		{
+			name:    "earlier last solve first",
+			ruleSet: "icpc",
+			a:       &model.Result{Score: 2, TotalTime: 100, SolvedTimes: []int64{30, 70}},
+			b:       &model.Result{Score: 2, TotalTime: 100, SolvedTimes: []int64{40, 60}},
+			want:    1,
+		},
+		{
+			name:    "earlier second to last solve first",
+			ruleSet: "ccpc",
+			a:       &model.Result{Score: 2, TotalTime: 100, SolvedTimes: []int64{30, 70}},
+			b:       &model.Result{Score: 2, TotalTime: 100, SolvedTimes: []int64{40, 70}},
+			want:    -1,
+		},
+		{
+			name:    "identical solve times tie",
+			ruleSet: "icpc",
+			a:       &model.Result{Score: 2, TotalTime: 100, SolvedTimes: []int64{30, 70}},
+			b:       &model.Result{Score: 2, TotalTime: 100, SolvedTimes: []int64{30, 70}},
+			want:    0,
+		},
+		{
+			name:      "allow ties skips solve times",
+			ruleSet:   "icpc",
+			allowTies: true,
+			a:         &model.Result{Score: 2, TotalTime: 100, SolvedTimes: []int64{30, 70}},
+			b:         &model.Result{Score: 2, TotalTime: 100, SolvedTimes: []int64{40, 60}},
+			want:      0,
+		},
+		{
+			name:    "oi ignores penalty",
+			ruleSet: "oi",
+			a:       &model.Result{Score: 150, TotalTime: 0},
+			b:       &model.Result{Score: 150, TotalTime: 300},
+			want:    0,
+		},
+		{
+			name:    "codeforces higher score first",
+			ruleSet: "codeforces",
+			a:       &model.Result{Score: 900},
+			b:       &model.Result{Score: 1200},
+			want:    1,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := modeltest.NewContest()
+			c.Options.RuleSet = tt.ruleSet
+			c.Options.AllowTies = tt.allowTies
+			got := c.RuleSet().Compare(tt.a, tt.b)
+			if sign(got) != tt.want {
+				t.Errorf("Compare() = %d, want sign %d", got, tt.want)
+			}
+		})
+	}
+}
+
+// sign 获取整数的符号
+func sign(n int) int {
+	switch {
+	case n < 0:
+		return -1
+	case n > 0:
+		return 1
+	}
+	return 0
+}
@@ -0,0 +1,95 @@
+package model
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+)
+
+// 数据异常类型
+const (
+	WarningUnsortedRuns        = "unsorted_runs"
+	WarningDuplicateSubmission = "duplicate_submission"
+)
+
+// Warning 表示处理数据时发现的异常
+type Warning struct {
+	Type         string `json:"type"`
+	SubmissionID string `json:"submission_id,omitempty"`
+	Message      string `json:"message"`
+}
+
+// PrepareRuns 整理提交记录，保证计分时的处理顺序确定
+// 提交编号重复时视为重判，保留文件中最后出现的记录；
+// 之后按（提交时间，提交编号）稳定排序。发现的异常通过 warnings 返回
+func PrepareRuns(runs []*Run) ([]*Run, []Warning) {
+	var warnings []Warning
+
+	// 记录每个提交编号最后一次出现的位置
+	lastIndex := make(map[string]int, len(runs))
+	for i, run := range runs {
+		if run.ID != "" {
+			lastIndex[run.ID] = i
+		}
+	}
+
+	// 去除被重判覆盖的记录
+	prepared := make([]*Run, 0, len(lastIndex))
+	for i, run := range runs {
+		if run.ID != "" && lastIndex[run.ID] != i {
+			last := runs[lastIndex[run.ID]]
+			warnings = append(warnings, Warning{
+				Type:         WarningDuplicateSubmission,
+				SubmissionID: run.ID,
+				Message:      fmt.Sprintf("duplicate submission %s: status %s replaced by %s", run.ID, run.Status, last.Status),
+			})
+			continue
+		}
+		prepared = append(prepared, run)
+	}
+
+	// 只有提交时间倒序才视为异常，时间相同时按编号调整顺序不提示
+	for i := 1; i < len(prepared); i++ {
+		if prepared[i].Time < prepared[i-1].Time {
+			warnings = append(warnings, Warning{
+				Type:         WarningUnsortedRuns,
+				SubmissionID: prepared[i].ID,
+				Message:      fmt.Sprintf("runs are not sorted by timestamp: a run at %v is listed after a run at %v", prepared[i].Time, prepared[i-1].Time),
+			})
+			break
+		}
+	}
+
+	less := func(i, j int) bool {
+		return RunLess(prepared[i], prepared[j])
+	}
+	if !sort.SliceIsSorted(prepared, less) {
+		sort.SliceStable(prepared, less)
+	}
+
+	return prepared, warnings
+}
+
+// RunLess 判断提交 a 是否排在 b 之前：按提交时间排序，时间相同时按提交编号排序
+func RunLess(a, b *Run) bool {
+	if a.Time != b.Time {
+		return a.Time < b.Time
+	}
+	return compareSubmissionID(a.ID, b.ID) < 0
+}
+
+// compareSubmissionID 比较提交编号，两者都是数字时按数值比较，否则按字符串比较
+func compareSubmissionID(a, b string) int {
+	x, errA := strconv.ParseInt(a, 10, 64)
+	y, errB := strconv.ParseInt(b, 10, 64)
+	if errA == nil && errB == nil {
+		return compareInt64(x, y)
+	}
+	switch {
+	case a < b:
+		return -1
+	case a > b:
+		return 1
+	}
+	return 0
+}
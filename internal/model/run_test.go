@@ -0,0 +1,87 @@
+package model_test
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/lllllan02/scoreboard/internal/model"
+)
+
+func TestPrepareRuns(t *testing.T) {
+	// run 创建 minute 分钟时的提交
+	run := func(id string, minute int, status string) *model.Run {
+		return &model.Run{ID: id, TeamID: "t1", Status: status, Time: time.Duration(minute) * time.Minute}
+	}
+
+	tests := []struct {
+		name         string
+		runs         []*model.Run
+		wantIDs      []string
+		wantStatuses []string
+		wantWarnings []string
+	}{
+		{
+			name:         "sorted runs are kept",
+			runs:         []*model.Run{run("1", 1, "WA"), run("2", 2, "AC"), run("3", 2, "WA")},
+			wantIDs:      []string{"1", "2", "3"},
+			wantStatuses: []string{"WA", "AC", "WA"},
+		},
+		{
+			name:         "unsorted runs are sorted by time",
+			runs:         []*model.Run{run("1", 5, "WA"), run("2", 2, "AC"), run("3", 3, "WA")},
+			wantIDs:      []string{"2", "3", "1"},
+			wantStatuses: []string{"AC", "WA", "WA"},
+			wantWarnings: []string{model.WarningUnsortedRuns},
+		},
+		{
+			// 时间相同的提交只调整顺序，不视为乱序
+			name:         "same time is ordered by numeric submission id",
+			runs:         []*model.Run{run("10", 1, "WA"), run("9", 1, "AC")},
+			wantIDs:      []string{"9", "10"},
+			wantStatuses: []string{"AC", "WA"},
+		},
+		{
+			name:         "rejudged submission keeps the last record",
+			runs:         []*model.Run{run("1", 1, "PENDING"), run("2", 2, "WA"), run("1", 1, "AC")},
+			wantIDs:      []string{"1", "2"},
+			wantStatuses: []string{"AC", "WA"},
+			wantWarnings: []string{model.WarningDuplicateSubmission, model.WarningUnsortedRuns},
+		},
+		{
+			name:         "runs without id are never merged",
+			runs:         []*model.Run{run("", 1, "WA"), run("", 1, "WA")},
+			wantIDs:      []string{"", ""},
+			wantStatuses: []string{"WA", "WA"},
+		},
+		{
+			name: "empty",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			prepared, warnings := model.PrepareRuns(tt.runs)
+
+			var ids, statuses []string
+			for _, run := range prepared {
+				ids = append(ids, run.ID)
+				statuses = append(statuses, run.Status)
+			}
+			var warningTypes []string
+			for _, warning := range warnings {
+				warningTypes = append(warningTypes, warning.Type)
+			}
+
+			if !reflect.DeepEqual(ids, tt.wantIDs) {
+				t.Errorf("ids = %v, want %v", ids, tt.wantIDs)
+			}
+			if !reflect.DeepEqual(statuses, tt.wantStatuses) {
+				t.Errorf("statuses = %v, want %v", statuses, tt.wantStatuses)
+			}
+			if !reflect.DeepEqual(warningTypes, tt.wantWarnings) {
+				t.Errorf("warnings = %v, want %v", warningTypes, tt.wantWarnings)
+			}
+		})
+	}
+}
@@ -0,0 +1,201 @@
+package model
+
+import (
+	"math"
+	"sort"
+	"time"
+)
+
+// LatestTime 表示不限截至时刻，统计全部提交，封榜状态按当前时间判断
+const LatestTime int64 = math.MaxInt64
+
+// Scorer 按比赛规则将提交计入队伍结果
+type Scorer struct {
+	contest     *Contest
+	rules       RuleSet
+	isPenalized func(Verdict) bool
+
+	elapsed     time.Duration // 截至时刻相对比赛开始的时长，之后的提交不统计
+	frozenStart time.Duration // 封榜开始时刻相对比赛开始的时长
+	frozen      bool          // 截至时刻记分板是否处于封榜状态
+}
+
+// NewScorer 创建截至 asOf 时刻（Unix时间戳，秒）的计分器，asOf 为 LatestTime 时统计全部提交
+func (c *Contest) NewScorer(asOf int64) *Scorer {
+	rules := c.RuleSet()
+
+	elapsed := time.Duration(math.MaxInt64)
+	if asOf != LatestTime {
+		elapsed = time.Duration(asOf-c.StartTime) * time.Second
+	}
+
+	return &Scorer{
+		contest:     c,
+		rules:       rules,
+		isPenalized: c.PenaltyPolicy(rules),
+		elapsed:     elapsed,
+		frozenStart: c.Duration() - time.Duration(c.FrozenTime)*time.Second,
+		frozen:      c.IsBoardFrozen(asOf, time.Now().Unix()),
+	}
+}
+
+// Frozen 判断计分器是否按封榜状态计分
+func (s *Scorer) Frozen() bool {
+	return s.frozen
+}
+
+// NewResult 创建队伍的空白结果
+func (s *Scorer) NewResult(teamID string, team *Team) *Result {
+	result := &Result{
+		TeamID:         teamID,
+		Team:           team,
+		ProblemResults: make(map[string]*ProblemResult),
+	}
+
+	// 初始化每个题目的结果
+	for _, problemID := range s.contest.ProblemIDs {
+		result.ProblemResults[problemID] = &ProblemResult{
+			ProblemID: problemID,
+		}
+	}
+
+	return result
+}
+
+// Apply 将一次提交计入队伍结果，同一题目的提交需要按时间顺序应用
+func (s *Scorer) Apply(result *Result, run *Run) {
+	// 跳过jury提交
+	if run.TeamID == "jury" {
+		return
+	}
+
+	// 跳过截至时刻之后的提交
+	if run.Time > s.elapsed {
+		return
+	}
+
+	// 查找对应题目ID
+	if run.ProblemID < 0 || run.ProblemID >= len(s.contest.ProblemIDs) {
+		return
+	}
+	problemID := s.contest.ProblemIDs[run.ProblemID]
+
+	// 获取题目结果
+	problemResult, ok := result.ProblemResults[problemID]
+	if !ok {
+		return
+	}
+
+	// 如果题目已解决，跳过后续提交
+	if problemResult.Solved {
+		return
+	}
+
+	// 检查是否在封榜时间内
+	// 结合截至时刻是否在封榜时间内，提交时间不早于封榜开始时刻，则在封榜范围内
+	isFrozen := s.frozen && run.Time >= s.frozenStart
+
+	// 等待评测和评测中的提交显示为待定
+	if run.Verdict.IsPending() {
+		if run.Verdict == VerdictFrozen {
+			problemResult.IsFrozen = true
+		}
+		problemResult.PendingAttempts++
+		return
+	}
+
+	// 只处理通过和计入错误尝试的提交
+	accepted := run.Verdict.IsAccepted()
+	if !accepted && !s.isPenalized(run.Verdict) {
+		return
+	}
+
+	if isFrozen {
+		problemResult.IsFrozen = true
+		problemResult.PendingAttempts++
+		return
+	}
+
+	// 按规则计算题目得分，题目只保留最高得分
+	if points := s.rules.ProblemScore(s.contest, run.ProblemID, problemResult, run); points > problemResult.Score {
+		result.Score += points - problemResult.Score
+		problemResult.Score = points
+	}
+
+	if accepted {
+		problemResult.Solved = true
+		// 通过时间以分钟为单位
+		problemResult.SolvedTime = run.Minute()
+		problemResult.SolvedAt = run.Time
+		// 按规则计算罚时
+		problemResult.PenaltyTime = s.rules.ProblemPenalty(s.contest, problemResult)
+
+		// 更新总时间（以分钟为单位），通过时间保持升序，用于排名时比较
+		result.TotalTime += problemResult.PenaltyTime
+		i := sort.Search(len(result.SolvedTimes), func(i int) bool {
+			return result.SolvedTimes[i] > problemResult.SolvedTime
+		})
+		result.SolvedTimes = append(result.SolvedTimes, 0)
+		copy(result.SolvedTimes[i+1:], result.SolvedTimes[i:])
+		result.SolvedTimes[i] = problemResult.SolvedTime
+	} else {
+		problemResult.Attempts++
+	}
+}
+
+// RescoreProblem 根据队伍在一道题目上的全部提交（按时间排序）重新计算该题目的结果
+// 用于重判等需要撤销已计入提交的情况
+func (s *Scorer) RescoreProblem(result *Result, problemIndex int, runs []*Run) {
+	if problemIndex < 0 || problemIndex >= len(s.contest.ProblemIDs) {
+		return
+	}
+	problemID := s.contest.ProblemIDs[problemIndex]
+
+	problemResult, ok := result.ProblemResults[problemID]
+	if !ok {
+		return
+	}
+
+	// 撤销该题目原有的得分和罚时
+	result.Score -= problemResult.Score
+	if problemResult.Solved {
+		result.TotalTime -= problemResult.PenaltyTime
+		for i, solvedTime := range result.SolvedTimes {
+			if solvedTime == problemResult.SolvedTime {
+				result.SolvedTimes = append(result.SolvedTimes[:i], result.SolvedTimes[i+1:]...)
+				break
+			}
+		}
+	}
+	*problemResult = ProblemResult{ProblemID: problemID}
+
+	for _, run := range runs {
+		s.Apply(result, run)
+	}
+}
+
+// ScoreRuns 计算队伍在给定提交（按时间排序）下截至 asOf 时刻的结果，不计算排名
+func (c *Contest) ScoreRuns(teams map[string]*Team, runs []*Run, asOf int64) []*Result {
+	scorer := c.NewScorer(asOf)
+
+	// 创建结果映射
+	resultsMap := make(map[string]*Result, len(teams))
+	for teamID, team := range teams {
+		resultsMap[teamID] = scorer.NewResult(teamID, team)
+	}
+
+	// 处理提交记录
+	for _, run := range runs {
+		if result, ok := resultsMap[run.TeamID]; ok {
+			scorer.Apply(result, run)
+		}
+	}
+
+	// 将结果映射转换为列表
+	resultsList := make([]*Result, 0, len(resultsMap))
+	for _, result := range resultsMap {
+		resultsList = append(resultsList, result)
+	}
+
+	return resultsList
+}
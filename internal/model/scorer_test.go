@@ -0,0 +1,191 @@
+package model_test
+
+import (
+	"testing"
+
+	"github.com/lllllan02/scoreboard/internal/model"
+	"github.com/lllllan02/scoreboard/internal/model/modeltest"
+)
+
+func TestScoreRuns(t *testing.T) {
+	tests := []struct {
+		name          string
+		ruleSet       string
+		options       func(c *model.Contest)
+		asOf          int64
+		runs          []*model.Run
+		wantScore     int
+		wantTotalTime int64
+		wantSolved    int
+		wantPending   int
+	}{
+		{
+			name:    "icpc counts compilation errors",
+			ruleSet: "icpc",
+			runs: []*model.Run{
+				modeltest.RunAt("1", "t1", 0, 10, "WRONG_ANSWER"),
+				modeltest.RunAt("2", "t1", 0, 20, "ACCEPTED"),
+				modeltest.RunAt("3", "t1", 1, 30, "COMPILATION_ERROR"),
+				modeltest.RunAt("4", "t1", 1, 40, "ACCEPTED"),
+			},
+			wantScore:     2,
+			wantTotalTime: 20 + 20 + 40 + 20,
+			wantSolved:    2,
+		},
+		{
+			name:    "ccpc ignores compilation errors",
+			ruleSet: "ccpc",
+			runs: []*model.Run{
+				modeltest.RunAt("1", "t1", 0, 10, "WRONG_ANSWER"),
+				modeltest.RunAt("2", "t1", 0, 20, "ACCEPTED"),
+				modeltest.RunAt("3", "t1", 1, 30, "COMPILATION_ERROR"),
+				modeltest.RunAt("4", "t1", 1, 40, "ACCEPTED"),
+			},
+			wantScore:     2,
+			wantTotalTime: 20 + 20 + 40,
+			wantSolved:    2,
+		},
+		{
+			name:    "runs after a problem is solved are ignored",
+			ruleSet: "icpc",
+			runs: []*model.Run{
+				modeltest.RunAt("1", "t1", 2, 50, "ACCEPTED"),
+				modeltest.RunAt("2", "t1", 2, 60, "WRONG_ANSWER"),
+				modeltest.RunAt("3", "t1", 2, 70, "ACCEPTED"),
+			},
+			wantScore:     1,
+			wantTotalTime: 50,
+			wantSolved:    1,
+		},
+		{
+			name:    "penalty verdicts override the rule",
+			ruleSet: "icpc",
+			options: func(c *model.Contest) { c.Options.PenaltyVerdicts = []string{"WA"} },
+			runs: []*model.Run{
+				modeltest.RunAt("1", "t1", 0, 10, "TIME_LIMIT_EXCEEDED"),
+				modeltest.RunAt("2", "t1", 0, 15, "WRONG_ANSWER"),
+				modeltest.RunAt("3", "t1", 0, 20, "ACCEPTED"),
+			},
+			wantScore:     1,
+			wantTotalTime: 20 + 20,
+			wantSolved:    1,
+		},
+		{
+			name:    "oi keeps the best partial score",
+			ruleSet: "oi",
+			runs: []*model.Run{
+				{ID: "1", TeamID: "t1", ProblemID: 0, Timestamp: 600, Status: "WRONG_ANSWER", Score: 30},
+				{ID: "2", TeamID: "t1", ProblemID: 0, Timestamp: 1200, Status: "WRONG_ANSWER", Score: 60},
+				{ID: "3", TeamID: "t1", ProblemID: 0, Timestamp: 1800, Status: "WRONG_ANSWER", Score: 40},
+				modeltest.RunAt("4", "t1", 1, 40, "ACCEPTED"),
+			},
+			wantScore:  60 + 100,
+			wantSolved: 1,
+		},
+		{
+			name:    "codeforces decays with time and attempts",
+			ruleSet: "codeforces",
+			runs: []*model.Run{
+				modeltest.RunAt("1", "t1", 0, 5, "WRONG_ANSWER"),
+				modeltest.RunAt("2", "t1", 0, 25, "ACCEPTED"),
+				modeltest.RunAt("3", "t1", 1, 0, "ACCEPTED"),
+				modeltest.RunAt("4", "t1", 2, 90, "COMPILATION_ERROR"),
+				modeltest.RunAt("5", "t1", 2, 100, "ACCEPTED"),
+			},
+			wantScore:  (500 - 50 - 50) + 1000 + (1500 - 600),
+			wantSolved: 3,
+		},
+		{
+			name:    "runs after the cutoff are not counted",
+			ruleSet: "icpc",
+			asOf:    modeltest.NewContest().StartTime + 30*60,
+			runs: []*model.Run{
+				modeltest.RunAt("1", "t1", 0, 20, "ACCEPTED"),
+				modeltest.RunAt("2", "t1", 1, 40, "ACCEPTED"),
+			},
+			wantScore:     1,
+			wantTotalTime: 20,
+			wantSolved:    1,
+		},
+		{
+			name:    "runs after the freeze are pending",
+			ruleSet: "icpc",
+			options: func(c *model.Contest) { c.FrozenTime = 3600 },
+			runs: []*model.Run{
+				modeltest.RunAt("1", "t1", 0, 20, "ACCEPTED"),
+				modeltest.RunAt("2", "t1", 1, 250, "ACCEPTED"),
+				modeltest.RunAt("3", "t1", 2, 280, "WRONG_ANSWER"),
+			},
+			wantScore:     1,
+			wantTotalTime: 20,
+			wantSolved:    1,
+			wantPending:   2,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := modeltest.NewContest()
+			c.Options.RuleSet = tt.ruleSet
+			if tt.options != nil {
+				tt.options(c)
+			}
+			c.NormalizeRuns(tt.runs)
+			asOf := tt.asOf
+			if asOf == 0 {
+				asOf = model.LatestTime
+			}
+
+			results := c.ScoreRuns(modeltest.NewTeams("t1"), tt.runs, asOf)
+			if len(results) != 1 {
+				t.Fatalf("got %d results, want 1", len(results))
+			}
+			result := results[0]
+			if result.Score != tt.wantScore {
+				t.Errorf("Score = %d, want %d", result.Score, tt.wantScore)
+			}
+			if result.TotalTime != tt.wantTotalTime {
+				t.Errorf("TotalTime = %d, want %d", result.TotalTime, tt.wantTotalTime)
+			}
+			if got := result.SolvedCount(); got != tt.wantSolved {
+				t.Errorf("SolvedCount() = %d, want %d", got, tt.wantSolved)
+			}
+			pending := 0
+			for _, problem := range result.ProblemResults {
+				pending += problem.PendingAttempts
+			}
+			if pending != tt.wantPending {
+				t.Errorf("pending attempts = %d, want %d", pending, tt.wantPending)
+			}
+		})
+	}
+}
+
+func TestRescoreProblem(t *testing.T) {
+	c := modeltest.NewContest()
+	runs := []*model.Run{
+		modeltest.RunAt("1", "t1", 0, 10, "WRONG_ANSWER"),
+		modeltest.RunAt("2", "t1", 0, 20, "ACCEPTED"),
+		modeltest.RunAt("3", "t1", 1, 30, "ACCEPTED"),
+	}
+	c.NormalizeRuns(runs)
+
+	scorer := c.NewScorer(model.LatestTime)
+	result := scorer.NewResult("t1", modeltest.NewTeams("t1")["t1"])
+	for _, run := range runs {
+		scorer.Apply(result, run)
+	}
+
+	// 重判后第一次提交通过，只重新计算题目 A
+	rejudged := *runs[0]
+	rejudged.Status = "ACCEPTED"
+	c.NormalizeRuns([]*model.Run{&rejudged})
+	scorer.RescoreProblem(result, 0, []*model.Run{&rejudged, runs[1]})
+
+	if result.Score != 2 || result.TotalTime != 10+30 {
+		t.Errorf("Score = %d, TotalTime = %d, want 2 and 40", result.Score, result.TotalTime)
+	}
+	if got := result.SolvedTimes; len(got) != 2 || got[0] != 10 || got[1] != 30 {
+		t.Errorf("SolvedTimes = %v, want [10 30]", got)
+	}
+}
@@ -0,0 +1,85 @@
+package model
+
+import (
+	"errors"
+)
+
+// ErrNotExist 要读取的数据不存在
+var ErrNotExist = errors.New("not found")
+
+// ContestStore 比赛数据的存储，包括比赛配置、队伍、提交记录、封榜状态和比赛目录
+type ContestStore interface {
+	// ListContests 列出存储中所有比赛的ID
+	ListContests() ([]string, error)
+	// Stamp 获取比赛数据的版本标记，用于判断数据是否变化
+	Stamp(contestID string) (ContestStamp, error)
+
+	// LoadConfig 读取比赛配置，返回的比赛只包含配置文件中的字段
+	LoadConfig(contestID string) (*Contest, error)
+	// LoadTeams 读取比赛的队伍
+	LoadTeams(contestID string) (map[string]*Team, error)
+	// LoadRuns 读取比赛的提交记录，时间戳保持原始单位
+	LoadRuns(contestID string) ([]*Run, error)
+	// LoadRaw 读取比赛原始的 config.json、team.json 和 run.json
+	LoadRaw(contestID string) (config, teams, runs []byte, err error)
+	// ImportContest 保存 xcpcio 格式的比赛配置、队伍和提交记录，已存在时覆盖
+	ImportContest(contestID string, config, teams, runs []byte) error
+
+	// LoadFreezeState 读取比赛的封榜状态，从未保存过时返回 nil
+	LoadFreezeState(contestID string) (*FreezeState, error)
+	// SaveFreezeState 保存比赛的封榜状态
+	SaveFreezeState(contestID string, state *FreezeState) error
+
+	// LoadDirectory 读取比赛目录，从未保存过时返回 ErrNotExist
+	LoadDirectory() (*ContestDirectory, error)
+	// UpdateDirectory 读取比赛目录（从未保存过时为空目录），调用 update 修改后保存
+	// 整个过程持有目录的锁，并发的更新不会互相覆盖；update 返回错误时不保存
+	UpdateDirectory(update func(directory *ContestDirectory) error) error
+}
+
+// ContestStamp 比赛数据各部分的版本标记，标记不同说明数据发生了变化
+type ContestStamp struct {
+	Config string // 比赛配置和封榜状态
+	Teams  string
+	Runs   string
+}
+
+// OnlyRunsChanged 判断相对 old 是否只有提交记录发生了变化
+func (s ContestStamp) OnlyRunsChanged(old ContestStamp) bool {
+	return s.Config == old.Config && s.Teams == old.Teams && s.Runs != old.Runs
+}
+
+// RunQuery 提交记录的查询条件
+type RunQuery struct {
+	TeamIDs      []string // 只返回这些队伍的提交，为 nil 时不限制
+	ProblemCount int      // 题目数量，题目编号不在 [0, ProblemCount) 内的提交被忽略
+	Offset       int
+	Limit        int
+}
+
+// RunQuerier 可以在存储内部筛选和分页提交记录的存储
+// 提交编号重复时只返回最后一条，结果按提交时间倒序排列，时间戳保持原始单位
+type RunQuerier interface {
+	// QueryRuns 返回一页提交记录和符合条件的提交总数
+	QueryRuns(contestID string, query RunQuery) ([]*Run, int, error)
+}
+
+// CopyContest 把比赛的原始数据和封榜状态从 src 复制到 dst
+func CopyContest(dst, src ContestStore, contestID string) error {
+	config, teams, runs, err := src.LoadRaw(contestID)
+	if err != nil {
+		return err
+	}
+	freeze, err := src.LoadFreezeState(contestID)
+	if err != nil {
+		return err
+	}
+
+	if err := dst.ImportContest(contestID, config, teams, runs); err != nil {
+		return err
+	}
+	if freeze != nil {
+		return dst.SaveFreezeState(contestID, freeze)
+	}
+	return nil
+}
@@ -0,0 +1,16 @@
+package model_test
+
+import (
+	"testing"
+
+	"github.com/lllllan02/scoreboard/internal/model"
+	"github.com/lllllan02/scoreboard/internal/model/modeltest"
+)
+
+func TestFileStore(t *testing.T) {
+	modeltest.TestContestStore(t, model.NewFileStore(t.TempDir()))
+}
+
+func TestMemoryStore(t *testing.T) {
+	modeltest.TestContestStore(t, model.NewMemoryStore())
+}
@@ -0,0 +1,48 @@
+package model
+
+import (
+	"strings"
+	"time"
+)
+
+// 提交时间戳单位（options.submission_timestamp_unit）
+const (
+	TimestampUnitSecond      = "second"
+	TimestampUnitMillisecond = "millisecond"
+	TimestampUnitMicrosecond = "microsecond"
+	TimestampUnitNanosecond  = "nanosecond"
+)
+
+// TimestampUnit 获取提交时间戳单位对应的时长，未配置或无法识别时按毫秒处理
+func (c *Contest) TimestampUnit() time.Duration {
+	switch strings.ToLower(c.Options.SubmissionTimestampUnit) {
+	case TimestampUnitSecond, "s":
+		return time.Second
+	case TimestampUnitMicrosecond, "us":
+		return time.Microsecond
+	case TimestampUnitNanosecond, "ns":
+		return time.Nanosecond
+	default:
+		return time.Millisecond
+	}
+}
+
+// NormalizeRuns 根据比赛的时间戳单位，将提交记录的时间戳统一转换为相对比赛开始的时长，
+// 同时统一评测结果的写法
+func (c *Contest) NormalizeRuns(runs []*Run) {
+	unit := c.TimestampUnit()
+	for _, run := range runs {
+		run.Time = time.Duration(run.Timestamp) * unit
+		run.Verdict = ParseVerdict(run.Status)
+	}
+}
+
+// Duration 获取比赛总时长
+func (c *Contest) Duration() time.Duration {
+	return time.Duration(c.EndTime-c.StartTime) * time.Second
+}
+
+// Minute 获取提交相对比赛开始的分钟数
+func (r *Run) Minute() int64 {
+	return int64(r.Time / time.Minute)
+}
@@ -0,0 +1,41 @@
+package model_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/lllllan02/scoreboard/internal/model"
+	"github.com/lllllan02/scoreboard/internal/model/modeltest"
+)
+
+func TestNormalizeRuns(t *testing.T) {
+	tests := []struct {
+		unit      string
+		timestamp int64
+		want      time.Duration
+	}{
+		{"second", 90, 90 * time.Second},
+		{"S", 90, 90 * time.Second},
+		{"millisecond", 90500, 90500 * time.Millisecond},
+		{"", 90500, 90500 * time.Millisecond},
+		{"unknown", 90500, 90500 * time.Millisecond},
+		{"microsecond", 90000001, 90000001 * time.Microsecond},
+		{"ns", 90, 90},
+	}
+
+	for _, tt := range tests {
+		c := modeltest.NewContest()
+		c.Options.SubmissionTimestampUnit = tt.unit
+		run := &model.Run{Timestamp: tt.timestamp}
+		c.NormalizeRuns([]*model.Run{run})
+		if run.Time != tt.want {
+			t.Errorf("unit %q: Time = %v, want %v", tt.unit, run.Time, tt.want)
+		}
+	}
+
+	// 分钟数向下取整
+	run := &model.Run{Time: 119 * time.Second}
+	if got := run.Minute(); got != 1 {
+		t.Errorf("Minute() = %d, want 1", got)
+	}
+}
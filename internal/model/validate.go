@@ -0,0 +1,281 @@
+package model
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// 数据检查发现的问题类型
+const (
+	IssueInvalidConfig        = "invalid_config"         // 比赛配置无法读取
+	IssueInvalidTeams         = "invalid_teams"          // 队伍数据无法读取
+	IssueInvalidRuns          = "invalid_runs"           // 提交记录无法读取
+	IssueInvalidTimeRange     = "invalid_time_range"     // 结束时间不晚于开始时间
+	IssueInvalidFrozenTime    = "invalid_frozen_time"    // 封榜时长为负数或超过比赛时长
+	IssueProblemCountMismatch = "problem_count_mismatch" // problem_quantity 与 problem_id 的数量不一致
+	IssueDuplicateProblem     = "duplicate_problem"      // 题目编号重复
+	IssueTeamIDMismatch       = "team_id_mismatch"       // 队伍的 team_id 与 team.json 中的键不一致
+	IssueUnknownGroup         = "unknown_group"          // 队伍的组别不在比赛配置的 group 中
+	IssueDuplicateTeam        = "duplicate_team"         // 不同编号的队伍队名和学校都相同
+	IssueProblemOutOfRange    = "problem_out_of_range"   // 提交的题目下标超出题目数量
+	IssueUnknownTeam          = "unknown_team"           // 提交的队伍不存在
+	IssueUnknownVerdict       = "unknown_verdict"        // 无法识别的评测结果
+	IssueRunBeforeStart       = "run_before_start"       // 提交时间早于比赛开始
+	IssueRunAfterEnd          = "run_after_end"          // 提交时间晚于比赛结束
+	IssueDuplicateRun         = "duplicate_run"          // 同一队伍在同一时刻对同一题目的相同提交出现多次
+)
+
+// Issue 数据检查发现的一个问题
+type Issue struct {
+	Type         string `json:"type"`
+	TeamID       string `json:"team_id,omitempty"`
+	SubmissionID string `json:"submission_id,omitempty"`
+	Message      string `json:"message"`
+}
+
+// ValidationReport 比赛数据的检查报告
+// Errors 中的问题会导致数据被计分时跳过或计算错误，Warnings 中的问题不影响计分但可能是数据错误
+type ValidationReport struct {
+	ContestID string  `json:"contest_id"`
+	Errors    []Issue `json:"errors"`
+	Warnings  []Issue `json:"warnings"`
+}
+
+// OK 判断检查是否没有发现错误
+func (r *ValidationReport) OK() bool {
+	return len(r.Errors) == 0
+}
+
+// addError 记录错误
+func (r *ValidationReport) addError(issue Issue) {
+	r.Errors = append(r.Errors, issue)
+}
+
+// addWarning 记录警告
+func (r *ValidationReport) addWarning(issue Issue) {
+	r.Warnings = append(r.Warnings, issue)
+}
+
+// ValidateStore 读取存储中的比赛并检查数据，数据无法读取时作为错误记录在报告中
+// 比赛不存在时返回错误
+func ValidateStore(store ContestStore, contestID string) (*ValidationReport, error) {
+	report := newValidationReport(contestID)
+
+	contest, err := LoadContestConfig(store, contestID)
+	if errors.Is(err, ErrNotExist) {
+		return nil, fmt.Errorf("contest not found: %w", err)
+	}
+	if err != nil {
+		report.addError(Issue{Type: IssueInvalidConfig, Message: err.Error()})
+		return report, nil
+	}
+
+	teams, err := contest.LoadTeams()
+	if err != nil {
+		report.addError(Issue{Type: IssueInvalidTeams, Message: err.Error()})
+	}
+	runs, err := store.LoadRuns(contestID)
+	if err != nil {
+		report.addError(Issue{Type: IssueInvalidRuns, Message: err.Error()})
+	}
+
+	contest.check(report, teams, runs)
+	return report, nil
+}
+
+// ValidateContest 检查比赛配置、队伍和提交记录是否一致
+// teams 为 nil 时不检查提交的队伍，提交时间会按比赛的时间戳单位重新换算
+func ValidateContest(contest *Contest, teams map[string]*Team, runs []*Run) *ValidationReport {
+	report := newValidationReport(contest.ID)
+	contest.check(report, teams, runs)
+	return report
+}
+
+// newValidationReport 创建空的检查报告，问题列表输出为 [] 而不是 null
+func newValidationReport(contestID string) *ValidationReport {
+	return &ValidationReport{ContestID: contestID, Errors: []Issue{}, Warnings: []Issue{}}
+}
+
+// check 依次检查比赛配置、队伍和提交记录
+func (c *Contest) check(report *ValidationReport, teams map[string]*Team, runs []*Run) {
+	c.validate(report)
+	if teams != nil {
+		c.validateTeams(report, teams)
+	}
+	c.validateRuns(report, teams, runs)
+}
+
+// validate 检查比赛配置
+func (c *Contest) validate(report *ValidationReport) {
+	if c.EndTime <= c.StartTime {
+		report.addError(Issue{
+			Type:    IssueInvalidTimeRange,
+			Message: fmt.Sprintf("end_time %d is not after start_time %d", c.EndTime, c.StartTime),
+		})
+	} else if c.FrozenTime < 0 || c.FrozenTime > c.EndTime-c.StartTime {
+		report.addWarning(Issue{
+			Type:    IssueInvalidFrozenTime,
+			Message: fmt.Sprintf("frozen_time %d is outside the contest duration of %d seconds", c.FrozenTime, c.EndTime-c.StartTime),
+		})
+	}
+
+	if c.ProblemCount != len(c.ProblemIDs) {
+		report.addError(Issue{
+			Type:    IssueProblemCountMismatch,
+			Message: fmt.Sprintf("problem_quantity is %d but problem_id has %d entries", c.ProblemCount, len(c.ProblemIDs)),
+		})
+	}
+
+	seen := make(map[string]bool, len(c.ProblemIDs))
+	for _, problemID := range c.ProblemIDs {
+		if seen[problemID] {
+			report.addError(Issue{
+				Type:    IssueDuplicateProblem,
+				Message: fmt.Sprintf("problem %q appears more than once in problem_id", problemID),
+			})
+		}
+		seen[problemID] = true
+	}
+}
+
+// validateTeams 检查队伍数据，按队伍编号的顺序输出问题
+func (c *Contest) validateTeams(report *ValidationReport, teams map[string]*Team) {
+	teamIDs := make([]string, 0, len(teams))
+	for teamID := range teams {
+		teamIDs = append(teamIDs, teamID)
+	}
+	sort.Slice(teamIDs, func(i, j int) bool {
+		return compareSubmissionID(teamIDs[i], teamIDs[j]) < 0
+	})
+
+	unknownGroups := make(map[string][]string)
+	names := make(map[string]string, len(teams))
+	for _, teamID := range teamIDs {
+		team := teams[teamID]
+		if team == nil {
+			report.addError(Issue{Type: IssueInvalidTeams, TeamID: teamID, Message: fmt.Sprintf("team %s is null", teamID)})
+			continue
+		}
+
+		if team.ID != "" && team.ID != teamID {
+			report.addWarning(Issue{
+				Type:    IssueTeamIDMismatch,
+				TeamID:  teamID,
+				Message: fmt.Sprintf("team %s has team_id %q", teamID, team.ID),
+			})
+		}
+
+		for _, group := range team.Groups {
+			if _, ok := c.Groups[group]; !ok {
+				unknownGroups[group] = append(unknownGroups[group], teamID)
+			}
+		}
+
+		key := team.Name + "\x00" + team.Organization
+		if other, ok := names[key]; ok {
+			report.addWarning(Issue{
+				Type:    IssueDuplicateTeam,
+				TeamID:  teamID,
+				Message: fmt.Sprintf("team %s has the same name and organization as team %s: %s (%s)", teamID, other, team.Name, team.Organization),
+			})
+		} else {
+			names[key] = teamID
+		}
+	}
+
+	// 未定义的组别通常涉及大量队伍，每个组别只报告一次
+	groups := make([]string, 0, len(unknownGroups))
+	for group := range unknownGroups {
+		groups = append(groups, group)
+	}
+	sort.Strings(groups)
+	for _, group := range groups {
+		teamIDs := unknownGroups[group]
+		report.addWarning(Issue{
+			Type:   IssueUnknownGroup,
+			TeamID: teamIDs[0],
+			Message: fmt.Sprintf("group %q of %d teams (%s) is not defined in the contest groups",
+				group, len(teamIDs), abbreviate(teamIDs, 5)),
+		})
+	}
+}
+
+// validateRuns 检查提交记录，按提交在文件中的顺序输出问题
+func (c *Contest) validateRuns(report *ValidationReport, teams map[string]*Team, runs []*Run) {
+	valid := make([]*Run, 0, len(runs))
+	for i, run := range runs {
+		if run == nil {
+			report.addError(Issue{Type: IssueInvalidRuns, Message: fmt.Sprintf("run #%d is null", i)})
+			continue
+		}
+		valid = append(valid, run)
+	}
+	c.NormalizeRuns(valid)
+
+	// 重复的提交编号和乱序沿用计分时的检查
+	_, warnings := PrepareRuns(valid)
+	for _, warning := range warnings {
+		report.addWarning(Issue{Type: warning.Type, SubmissionID: warning.SubmissionID, Message: warning.Message})
+	}
+
+	duration := c.Duration()
+	seen := make(map[string]string, len(runs))
+	for i, run := range runs {
+		if run == nil {
+			continue
+		}
+
+		// 没有提交编号的记录用位置标识
+		label := run.ID
+		if label == "" {
+			label = fmt.Sprintf("#%d", i)
+		}
+		issue := func(issueType, format string, args ...interface{}) Issue {
+			return Issue{
+				Type:         issueType,
+				TeamID:       run.TeamID,
+				SubmissionID: run.ID,
+				Message:      fmt.Sprintf("run %s: ", label) + fmt.Sprintf(format, args...),
+			}
+		}
+
+		if run.ProblemID < 0 || run.ProblemID >= len(c.ProblemIDs) {
+			report.addError(issue(IssueProblemOutOfRange, "problem_id %d is out of range [0, %d)", run.ProblemID, len(c.ProblemIDs)))
+		}
+
+		if teams != nil && run.TeamID != "jury" {
+			if _, ok := teams[run.TeamID]; !ok {
+				report.addError(issue(IssueUnknownTeam, "team %q does not exist", run.TeamID))
+			}
+		}
+
+		if run.Verdict == VerdictUnknown {
+			report.addWarning(issue(IssueUnknownVerdict, "status %q is not a known verdict", run.Status))
+		}
+
+		switch {
+		case run.Time < 0:
+			report.addError(issue(IssueRunBeforeStart, "submitted %s before the contest start", -run.Time))
+		case run.Time > duration:
+			report.addError(issue(IssueRunAfterEnd, "submitted %s after the contest end", run.Time-duration))
+		}
+
+		// 提交编号不同但内容完全相同的记录可能是重复导入
+		key := fmt.Sprintf("%s\x00%d\x00%d\x00%s\x00%s", run.TeamID, run.ProblemID, run.Timestamp, run.Status, run.Language)
+		if other, ok := seen[key]; ok && other != label {
+			report.addWarning(issue(IssueDuplicateRun, "same team, problem, timestamp and status as run %s", other))
+		} else if !ok {
+			seen[key] = label
+		}
+	}
+}
+
+// abbreviate 列出前 n 个编号，其余以数量代替
+func abbreviate(ids []string, n int) string {
+	if len(ids) <= n {
+		return strings.Join(ids, ", ")
+	}
+	return fmt.Sprintf("%s and %d more", strings.Join(ids[:n], ", "), len(ids)-n)
+}
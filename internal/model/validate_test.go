@@ -0,0 +1,143 @@
+package model_test
+
+import (
+	"reflect"
+	"sort"
+	"testing"
+
+	"github.com/lllllan02/scoreboard/internal/model"
+	"github.com/lllllan02/scoreboard/internal/model/modeltest"
+)
+
+func TestValidateContest(t *testing.T) {
+	tests := []struct {
+		name         string
+		contest      func(c *model.Contest)
+		teams        map[string]*model.Team
+		runs         []*model.Run
+		wantErrors   []string
+		wantWarnings []string
+	}{
+		{
+			name:  "valid",
+			teams: modeltest.NewTeams("t1", "t2"),
+			runs: []*model.Run{
+				modeltest.RunAt("1", "t1", 0, 10, "WRONG_ANSWER"),
+				modeltest.RunAt("2", "t2", 1, 20, "ACCEPTED"),
+				modeltest.RunAt("3", "jury", 2, 30, "ACCEPTED"),
+			},
+		},
+		{
+			name:       "invalid time range",
+			contest:    func(c *model.Contest) { c.EndTime = c.StartTime },
+			wantErrors: []string{model.IssueInvalidTimeRange},
+		},
+		{
+			name:         "frozen time longer than the contest",
+			contest:      func(c *model.Contest) { c.FrozenTime = 6 * 3600 },
+			wantWarnings: []string{model.IssueInvalidFrozenTime},
+		},
+		{
+			name:       "problem count mismatch and duplicate problem",
+			contest:    func(c *model.Contest) { c.ProblemIDs = []string{"A", "B", "B", "C"} },
+			wantErrors: []string{model.IssueDuplicateProblem, model.IssueProblemCountMismatch},
+		},
+		{
+			name: "team problems",
+			teams: map[string]*model.Team{
+				"t1": {ID: "t1", Name: "Same", Organization: "School", Groups: []string{"official"}},
+				"t2": {ID: "t3", Name: "Same", Organization: "School", Groups: []string{"girls"}},
+			},
+			wantWarnings: []string{model.IssueDuplicateTeam, model.IssueTeamIDMismatch, model.IssueUnknownGroup},
+		},
+		{
+			name:  "run problems",
+			teams: modeltest.NewTeams("t1"),
+			runs: []*model.Run{
+				modeltest.RunAt("1", "t1", 3, 10, "ACCEPTED"),
+				modeltest.RunAt("2", "t9", 0, 20, "ACCEPTED"),
+				modeltest.RunAt("3", "t1", 0, 30, "GREAT_SUCCESS"),
+				modeltest.RunAt("4", "t1", 1, -5, "WRONG_ANSWER"),
+				modeltest.RunAt("5", "t1", 1, 301, "WRONG_ANSWER"),
+			},
+			wantErrors:   []string{model.IssueProblemOutOfRange, model.IssueRunAfterEnd, model.IssueRunBeforeStart, model.IssueUnknownTeam},
+			wantWarnings: []string{model.IssueUnknownVerdict, model.WarningUnsortedRuns},
+		},
+		{
+			name:  "teams are not checked when missing",
+			teams: nil,
+			runs:  []*model.Run{modeltest.RunAt("1", "t9", 0, 10, "ACCEPTED")},
+		},
+		{
+			name:  "duplicate runs",
+			teams: modeltest.NewTeams("t1"),
+			runs: []*model.Run{
+				modeltest.RunAt("1", "t1", 0, 10, "WRONG_ANSWER"),
+				modeltest.RunAt("2", "t1", 0, 10, "WRONG_ANSWER"),
+				modeltest.RunAt("2", "t1", 0, 10, "ACCEPTED"),
+			},
+			wantWarnings: []string{model.IssueDuplicateRun, model.WarningDuplicateSubmission},
+		},
+		{
+			name:       "null run",
+			teams:      modeltest.NewTeams("t1"),
+			runs:       []*model.Run{modeltest.RunAt("1", "t1", 0, 10, "ACCEPTED"), nil},
+			wantErrors: []string{model.IssueInvalidRuns},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := modeltest.NewContest()
+			if tt.contest != nil {
+				tt.contest(c)
+			}
+
+			report := model.ValidateContest(c, tt.teams, tt.runs)
+			if got := issueTypes(report.Errors); !reflect.DeepEqual(got, tt.wantErrors) {
+				t.Errorf("errors = %v, want %v\n%+v", got, tt.wantErrors, report.Errors)
+			}
+			if got := issueTypes(report.Warnings); !reflect.DeepEqual(got, tt.wantWarnings) {
+				t.Errorf("warnings = %v, want %v\n%+v", got, tt.wantWarnings, report.Warnings)
+			}
+			if report.OK() != (len(tt.wantErrors) == 0) {
+				t.Errorf("OK() = %v with errors %v", report.OK(), report.Errors)
+			}
+		})
+	}
+}
+
+func TestValidateStore(t *testing.T) {
+	store := model.NewMemoryStore()
+	c := modeltest.NewContest()
+	runs := []*model.Run{modeltest.RunAt("1", "t1", 0, 10, "ACCEPTED"), modeltest.RunAt("2", "t2", 0, 20, "ACCEPTED")}
+	if err := store.PutContest(c.ID, c, modeltest.NewTeams("t1"), runs); err != nil {
+		t.Fatal(err)
+	}
+
+	report, err := model.ValidateStore(store, c.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := issueTypes(report.Errors); !reflect.DeepEqual(got, []string{model.IssueUnknownTeam}) {
+		t.Errorf("errors = %v, want [%s]", got, model.IssueUnknownTeam)
+	}
+
+	if _, err := model.ValidateStore(store, "missing"); err == nil {
+		t.Error("ValidateStore of a missing contest returned no error")
+	}
+}
+
+// issueTypes 获取问题类型，去重并排序
+func issueTypes(issues []model.Issue) []string {
+	seen := make(map[string]bool)
+	var types []string
+	for _, issue := range issues {
+		if !seen[issue.Type] {
+			seen[issue.Type] = true
+			types = append(types, issue.Type)
+		}
+	}
+	sort.Strings(types)
+	return types
+}
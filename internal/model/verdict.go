@@ -0,0 +1,134 @@
+package model
+
+import "strings"
+
+// Verdict 表示提交的评测结果
+type Verdict string
+
+// 评测结果
+const (
+	VerdictAccepted              Verdict = "ACCEPTED"
+	VerdictWrongAnswer           Verdict = "WRONG_ANSWER"
+	VerdictTimeLimitExceeded     Verdict = "TIME_LIMIT_EXCEEDED"
+	VerdictMemoryLimitExceeded   Verdict = "MEMORY_LIMIT_EXCEEDED"
+	VerdictOutputLimitExceeded   Verdict = "OUTPUT_LIMIT_EXCEEDED"
+	VerdictIdlenessLimitExceeded Verdict = "IDLENESS_LIMIT_EXCEEDED"
+	VerdictRuntimeError          Verdict = "RUNTIME_ERROR"
+	VerdictCompilationError      Verdict = "COMPILATION_ERROR"
+	VerdictPresentationError     Verdict = "PRESENTATION_ERROR"
+	VerdictNoOutput              Verdict = "NO_OUTPUT"
+	VerdictSecurityViolation     Verdict = "SECURITY_VIOLATION"
+	VerdictJudgementFailed       Verdict = "JUDGEMENT_FAILED"
+	VerdictPending               Verdict = "PENDING"
+	VerdictJudging               Verdict = "JUDGING"
+	VerdictFrozen                Verdict = "FROZEN"
+	VerdictSkipped               Verdict = "SKIPPED"
+	VerdictUnknown               Verdict = "UNKNOWN"
+)
+
+// verdictAliases 各数据源（xcpcio、DOMjudge、Codeforces 等）中评测结果的不同写法
+// 键为转换成大写并将空格、连字符替换为下划线后的写法
+var verdictAliases = map[string]Verdict{
+	"AC":                      VerdictAccepted,
+	"OK":                      VerdictAccepted,
+	"CORRECT":                 VerdictAccepted,
+	"YES":                     VerdictAccepted,
+	"WA":                      VerdictWrongAnswer,
+	"WRONG":                   VerdictWrongAnswer,
+	"INCORRECT":               VerdictWrongAnswer,
+	"REJECTED":                VerdictWrongAnswer,
+	"TLE":                     VerdictTimeLimitExceeded,
+	"TIMELIMIT":               VerdictTimeLimitExceeded,
+	"TIME_LIMIT":              VerdictTimeLimitExceeded,
+	"MLE":                     VerdictMemoryLimitExceeded,
+	"MEMORY_LIMIT":            VerdictMemoryLimitExceeded,
+	"OLE":                     VerdictOutputLimitExceeded,
+	"OUTPUT_LIMIT":            VerdictOutputLimitExceeded,
+	"ILE":                     VerdictIdlenessLimitExceeded,
+	"RE":                      VerdictRuntimeError,
+	"RTE":                     VerdictRuntimeError,
+	"RUN_ERROR":               VerdictRuntimeError,
+	"CE":                      VerdictCompilationError,
+	"COMPILE_ERROR":           VerdictCompilationError,
+	"COMPILER_ERROR":          VerdictCompilationError,
+	"PE":                      VerdictPresentationError,
+	"PRESENTATION":            VerdictPresentationError,
+	"WRONG_OUTPUT_FORMAT":     VerdictPresentationError,
+	"NO_OUTPUT":               VerdictNoOutput,
+	"JUDGEMENT_FAILED":        VerdictJudgementFailed,
+	"JUDGE_ERROR":             VerdictJudgementFailed,
+	"SYSTEM_ERROR":            VerdictJudgementFailed,
+	"SECURITY_VIOLATION":      VerdictSecurityViolation,
+	"PD":                      VerdictPending,
+	"QUEUED":                  VerdictPending,
+	"WAITING":                 VerdictPending,
+	"SUBMITTED":               VerdictPending,
+	"TESTING":                 VerdictJudging,
+	"RUNNING":                 VerdictJudging,
+	"COMPILING":               VerdictJudging,
+	"JUDGING":                 VerdictJudging,
+	"FROZEN":                  VerdictFrozen,
+	"SKIPPED":                 VerdictSkipped,
+	"IGNORED":                 VerdictSkipped,
+	"CANCELLED":               VerdictSkipped,
+	"PENDING":                 VerdictPending,
+	"ACCEPTED":                VerdictAccepted,
+	"WRONG_ANSWER":            VerdictWrongAnswer,
+	"TIME_LIMIT_EXCEEDED":     VerdictTimeLimitExceeded,
+	"MEMORY_LIMIT_EXCEEDED":   VerdictMemoryLimitExceeded,
+	"OUTPUT_LIMIT_EXCEEDED":   VerdictOutputLimitExceeded,
+	"RUNTIME_ERROR":           VerdictRuntimeError,
+	"COMPILATION_ERROR":       VerdictCompilationError,
+	"PRESENTATION_ERROR":      VerdictPresentationError,
+	"IDLENESS_LIMIT_EXCEEDED": VerdictIdlenessLimitExceeded,
+}
+
+// ParseVerdict 将各种写法的评测结果统一为 Verdict，无法识别时返回 VerdictUnknown
+func ParseVerdict(status string) Verdict {
+	key := strings.ToUpper(strings.TrimSpace(status))
+	key = strings.NewReplacer(" ", "_", "-", "_").Replace(key)
+	if verdict, ok := verdictAliases[key]; ok {
+		return verdict
+	}
+	return VerdictUnknown
+}
+
+// IsAccepted 判断是否为通过
+func (v Verdict) IsAccepted() bool {
+	return v == VerdictAccepted
+}
+
+// IsPending 判断是否为尚未出结果的提交（等待评测、评测中或封榜）
+func (v Verdict) IsPending() bool {
+	switch v {
+	case VerdictPending, VerdictJudging, VerdictFrozen:
+		return true
+	}
+	return false
+}
+
+// IsRejected 判断是否为未通过的评测结果
+// 评测失败、跳过和无法识别的结果不属于未通过
+func (v Verdict) IsRejected() bool {
+	switch v {
+	case VerdictAccepted, VerdictJudgementFailed, VerdictSkipped, VerdictUnknown:
+		return false
+	}
+	return !v.IsPending()
+}
+
+// PenaltyPolicy 获取判断评测结果是否计入错误尝试的函数
+// 配置了 options.penalty_verdicts 时以配置为准，否则使用计分规则的默认设置
+func (c *Contest) PenaltyPolicy(rules RuleSet) func(Verdict) bool {
+	if len(c.Options.PenaltyVerdicts) == 0 {
+		return rules.IsPenalized
+	}
+
+	penalized := make(map[Verdict]bool)
+	for _, status := range c.Options.PenaltyVerdicts {
+		penalized[ParseVerdict(status)] = true
+	}
+	return func(v Verdict) bool {
+		return penalized[v]
+	}
+}
@@ -0,0 +1,103 @@
+package model_test
+
+import (
+	"testing"
+
+	"github.com/lllllan02/scoreboard/internal/model"
+	"github.com/lllllan02/scoreboard/internal/model/modeltest"
+)
+
+func TestParseVerdict(t *testing.T) {
+	tests := []struct {
+		status string
+		want   model.Verdict
+	}{
+		{"ACCEPTED", model.VerdictAccepted},
+		{"accepted", model.VerdictAccepted},
+		{"AC", model.VerdictAccepted},
+		{"OK", model.VerdictAccepted},
+		{"correct", model.VerdictAccepted},
+		{"WRONG_ANSWER", model.VerdictWrongAnswer},
+		{"Wrong Answer", model.VerdictWrongAnswer},
+		{"wrong-answer", model.VerdictWrongAnswer},
+		{" WA ", model.VerdictWrongAnswer},
+		{"TLE", model.VerdictTimeLimitExceeded},
+		{"Time Limit Exceeded", model.VerdictTimeLimitExceeded},
+		{"MLE", model.VerdictMemoryLimitExceeded},
+		{"RE", model.VerdictRuntimeError},
+		{"run-error", model.VerdictRuntimeError},
+		{"CE", model.VerdictCompilationError},
+		{"Compile Error", model.VerdictCompilationError},
+		{"PE", model.VerdictPresentationError},
+		{"judge error", model.VerdictJudgementFailed},
+		{"PENDING", model.VerdictPending},
+		{"queued", model.VerdictPending},
+		{"RUNNING", model.VerdictJudging},
+		{"frozen", model.VerdictFrozen},
+		{"CANCELLED", model.VerdictSkipped},
+		{"", model.VerdictUnknown},
+		{"GREAT_SUCCESS", model.VerdictUnknown},
+	}
+
+	for _, tt := range tests {
+		if got := model.ParseVerdict(tt.status); got != tt.want {
+			t.Errorf("ParseVerdict(%q) = %s, want %s", tt.status, got, tt.want)
+		}
+	}
+}
+
+func TestVerdictClasses(t *testing.T) {
+	tests := []struct {
+		verdict                     model.Verdict
+		accepted, pending, rejected bool
+	}{
+		{model.VerdictAccepted, true, false, false},
+		{model.VerdictWrongAnswer, false, false, true},
+		{model.VerdictCompilationError, false, false, true},
+		{model.VerdictPending, false, true, false},
+		{model.VerdictJudging, false, true, false},
+		{model.VerdictFrozen, false, true, false},
+		{model.VerdictJudgementFailed, false, false, false},
+		{model.VerdictSkipped, false, false, false},
+		{model.VerdictUnknown, false, false, false},
+	}
+
+	for _, tt := range tests {
+		if got := tt.verdict.IsAccepted(); got != tt.accepted {
+			t.Errorf("%s.IsAccepted() = %v, want %v", tt.verdict, got, tt.accepted)
+		}
+		if got := tt.verdict.IsPending(); got != tt.pending {
+			t.Errorf("%s.IsPending() = %v, want %v", tt.verdict, got, tt.pending)
+		}
+		if got := tt.verdict.IsRejected(); got != tt.rejected {
+			t.Errorf("%s.IsRejected() = %v, want %v", tt.verdict, got, tt.rejected)
+		}
+	}
+}
+
+func TestPenaltyPolicy(t *testing.T) {
+	tests := []struct {
+		name            string
+		ruleSet         string
+		penaltyVerdicts []string
+		verdict         model.Verdict
+		want            bool
+	}{
+		{"rule default", "icpc", nil, model.VerdictCompilationError, true},
+		{"rule default without compilation errors", "ccpc", nil, model.VerdictCompilationError, false},
+		{"configured verdict", "icpc", []string{"WA", "tle"}, model.VerdictTimeLimitExceeded, true},
+		{"verdict not configured", "icpc", []string{"WA", "tle"}, model.VerdictRuntimeError, false},
+		{"configured verdict overrides the rule", "ccpc", []string{"CE"}, model.VerdictCompilationError, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := modeltest.NewContest()
+			c.Options.RuleSet = tt.ruleSet
+			c.Options.PenaltyVerdicts = tt.penaltyVerdicts
+			if got := c.PenaltyPolicy(c.RuleSet())(tt.verdict); got != tt.want {
+				t.Errorf("PenaltyPolicy()(%s) = %v, want %v", tt.verdict, got, tt.want)
+			}
+		})
+	}
+}
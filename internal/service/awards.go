@@ -0,0 +1,143 @@
+package service
+
+import (
+	"sort"
+
+	"github.com/lllllan02/scoreboard/internal/model"
+)
+
+// AssignMedals 根据比赛配置的奖牌名额为队伍颁发奖牌
+// results 需要已经按排名排序，打星队伍和未解题的队伍不参与评奖
+// medalRanks 形如 {"official": {"gold": 28, "silver": 56, "bronze": 84}}，
+// 每个分组内按组内排名依次颁发金、银、铜牌，数字为该奖牌的名额
+func AssignMedals(results []*model.Result, medalRanks map[string]map[string]int) {
+	// 清除之前的奖牌
+	for _, result := range results {
+		result.Medal = ""
+	}
+
+	// 按分组名称排序，保证同一队伍属于多个评奖分组时结果稳定
+	groups := make([]string, 0, len(medalRanks))
+	for group := range medalRanks {
+		groups = append(groups, group)
+	}
+	sort.Strings(groups)
+
+	for _, group := range groups {
+		quotas := medalRanks[group]
+
+		// 计算组内排名，排名并列的队伍获得相同的组内排名
+		groupRank := 0
+		prevRank := 0
+		count := 0
+		for _, result := range results {
+			if !result.Team.IsOfficial() || !inMedalGroup(result.Team, group) {
+				continue
+			}
+
+			count++
+			if result.Rank != prevRank {
+				groupRank = count
+				prevRank = result.Rank
+			}
+
+			// 未解题的队伍不颁发奖牌，已获得奖牌的队伍不重复颁发
+			if result.Score == 0 || result.Medal != "" {
+				continue
+			}
+
+			// 依次检查金、银、铜牌的名额
+			limit := 0
+			for _, medal := range model.MedalTypes {
+				limit += quotas[medal]
+				if groupRank <= limit {
+					result.Medal = medal
+					break
+				}
+			}
+		}
+	}
+}
+
+// inMedalGroup 判断队伍是否属于评奖分组
+// "official" 表示所有正式队伍，其他分组按队伍的组别判断
+func inMedalGroup(team *model.Team, group string) bool {
+	if group == "official" {
+		return true
+	}
+	for _, g := range team.Groups {
+		if g == group {
+			return true
+		}
+	}
+	return false
+}
+
+// FirstSolve 表示一道题目的一血
+type FirstSolve struct {
+	ProblemID    string `json:"problem_id"`
+	TeamID       string `json:"team_id"`
+	TeamName     string `json:"team_name"`
+	Organization string `json:"organization"`
+	SolvedTime   int64  `json:"solved_time"` // 通过时间（分钟）
+}
+
+// FindFirstSolves 按题目顺序找出每道题目最早通过的队伍
+// 多个队伍在同一时刻通过时都算作一血；打星队伍是否参与由比赛配置决定
+func FindFirstSolves(results []*model.Result, contest *model.Contest) []FirstSolve {
+	var firstSolves []FirstSolve
+	for _, problemID := range contest.ProblemIDs {
+		var earliest []*model.Result
+		for _, result := range results {
+			if !result.Team.IsOfficial() && !contest.Options.FirstSolveIncludeUnofficial {
+				continue
+			}
+
+			problem, ok := result.ProblemResults[problemID]
+			if !ok || !problem.Solved {
+				continue
+			}
+
+			switch {
+			case len(earliest) == 0 || problem.SolvedAt < earliest[0].ProblemResults[problemID].SolvedAt:
+				earliest = []*model.Result{result}
+			case problem.SolvedAt == earliest[0].ProblemResults[problemID].SolvedAt:
+				earliest = append(earliest, result)
+			}
+		}
+
+		for _, result := range earliest {
+			firstSolves = append(firstSolves, FirstSolve{
+				ProblemID:    problemID,
+				TeamID:       result.TeamID,
+				TeamName:     result.Team.Name,
+				Organization: result.Team.Organization,
+				SolvedTime:   result.ProblemResults[problemID].SolvedTime,
+			})
+		}
+	}
+
+	return firstSolves
+}
+
+// MarkGlobalFirstSolves 在全部队伍上计算一血并标记 FirstToSolveGlobal，返回一血列表
+// 需要在筛选之前调用，筛选后的首A由 RecalculateRanking 标记在 FirstToSolve 上
+func MarkGlobalFirstSolves(results []*model.Result, contest *model.Contest) []FirstSolve {
+	firstSolves := FindFirstSolves(results, contest)
+	for _, result := range results {
+		for problemID, problem := range result.ProblemResults {
+			problem.FirstToSolveGlobal = isFirstSolve(firstSolves, problemID, result.TeamID)
+		}
+	}
+	return firstSolves
+}
+
+// isFirstSolve 判断队伍是否获得了题目的一血
+func isFirstSolve(firstSolves []FirstSolve, problemID, teamID string) bool {
+	for _, firstSolve := range firstSolves {
+		if firstSolve.ProblemID == problemID && firstSolve.TeamID == teamID {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,94 @@
+package service
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/lllllan02/scoreboard/internal/model"
+)
+
+// rankedResult 创建已排名的队伍结果
+func rankedResult(teamID string, rank, score int, groups ...string) *model.Result {
+	return &model.Result{
+		TeamID: teamID,
+		Team:   &model.Team{ID: teamID, Name: "Team " + teamID, Groups: groups},
+		Rank:   rank,
+		Score:  score,
+	}
+}
+
+func TestAssignMedals(t *testing.T) {
+	tests := []struct {
+		name       string
+		results    []*model.Result
+		medalRanks map[string]map[string]int
+		want       []string
+	}{
+		{
+			name: "medals in rank order",
+			results: []*model.Result{
+				rankedResult("t1", 1, 5),
+				rankedResult("t2", 2, 4),
+				rankedResult("t3", 3, 3),
+				rankedResult("t4", 4, 2),
+				rankedResult("t5", 5, 1),
+			},
+			medalRanks: map[string]map[string]int{"official": {"gold": 1, "silver": 1, "bronze": 2}},
+			want:       []string{"gold", "silver", "bronze", "bronze", ""},
+		},
+		{
+			name: "ties at a cutoff share the medal",
+			results: []*model.Result{
+				rankedResult("t1", 1, 3),
+				rankedResult("t2", 2, 2),
+				rankedResult("t3", 2, 2),
+				rankedResult("t4", 4, 1),
+			},
+			medalRanks: map[string]map[string]int{"official": {"gold": 1, "silver": 1, "bronze": 1}},
+			want:       []string{"gold", "silver", "silver", ""},
+		},
+		{
+			name: "unofficial and unsolved teams take no medal",
+			results: []*model.Result{
+				rankedResult("t1", 1, 3),
+				rankedResult("t2", 2, 2, "unofficial"),
+				rankedResult("t3", 3, 1),
+				rankedResult("t4", 4, 0),
+			},
+			medalRanks: map[string]map[string]int{"official": {"gold": 1, "silver": 1, "bronze": 1}},
+			want:       []string{"gold", "", "silver", ""},
+		},
+		{
+			name: "group quotas",
+			results: []*model.Result{
+				rankedResult("t1", 1, 3),
+				rankedResult("t2", 2, 2, "girls"),
+				rankedResult("t3", 3, 1),
+			},
+			medalRanks: map[string]map[string]int{
+				"official": {"gold": 1},
+				"girls":    {"gold": 1},
+			},
+			want: []string{"gold", "gold", ""},
+		},
+		{
+			name:    "no medal config",
+			results: []*model.Result{{TeamID: "t1", Team: &model.Team{ID: "t1"}, Rank: 1, Score: 1, Medal: "gold"}},
+			want:    []string{""},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			AssignMedals(tt.results, tt.medalRanks)
+
+			var got []string
+			for _, result := range tt.results {
+				got = append(got, result.Medal)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("medals = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,194 @@
+package service
+
+import (
+	"container/list"
+	"fmt"
+	"sync"
+	"time"
+
+	"golang.org/x/sync/singleflight"
+
+	"github.com/lllllan02/scoreboard/internal/model"
+)
+
+// defaultCacheSize 默认的缓存容量，以缓存的队伍和提交总数估算占用的内存
+const defaultCacheSize = 1 << 20
+
+// cacheEntry 缓存的比赛数据
+type cacheEntry struct {
+	contestID string
+	engine    *Engine
+	stamp     model.ContestStamp // 加载时比赛数据的版本标记
+	stale     bool               // 已被标记失效，下次访问时重新加载
+	size      int                // 队伍和提交的数量
+}
+
+// contestCache 按比赛ID缓存解析后的比赛数据和计分引擎
+// 每次访问时检查存储中比赛数据的版本标记（文件存储为修改时间和大小）：只有提交记录变化时增量同步，
+// 其他数据变化时重新加载整个比赛。同一比赛的并发加载通过 singleflight 合并，
+// 缓存的队伍和提交总数超过容量时淘汰最久未访问的比赛，最近访问的比赛总会保留
+type contestCache struct {
+	store model.ContestStore
+
+	mu       sync.Mutex
+	capacity int                      // 最多缓存的队伍和提交总数
+	size     int                      // 当前缓存的队伍和提交总数
+	entries  map[string]*list.Element // 比赛ID -> lru 中的元素
+	lru      *list.List               // 元素为 *cacheEntry，最近访问的在前
+	group    singleflight.Group
+}
+
+// newContestCache 创建最多缓存 capacity 条队伍和提交的缓存
+func newContestCache(store model.ContestStore, capacity int) *contestCache {
+	if capacity <= 0 {
+		capacity = defaultCacheSize
+	}
+	return &contestCache{
+		store:    store,
+		capacity: capacity,
+		entries:  make(map[string]*list.Element),
+		lru:      list.New(),
+	}
+}
+
+// get 获取比赛的计分引擎，比赛数据变化时先更新
+func (c *contestCache) get(contestID string) (*Engine, error) {
+	stamp, err := c.store.Stamp(contestID)
+	if err != nil {
+		return nil, fmt.Errorf("contest not found: %s", err)
+	}
+
+	if entry := c.lookup(contestID); entry != nil && entry.fresh(stamp) {
+		return entry.engine, nil
+	}
+
+	// 同一比赛的加载只执行一次，其他请求等待并共享结果
+	engine, err, _ := c.group.Do(contestID, func() (interface{}, error) {
+		return c.load(contestID, stamp)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return engine.(*Engine), nil
+}
+
+// load 加载或更新比赛数据并放入缓存
+func (c *contestCache) load(contestID string, stamp model.ContestStamp) (*Engine, error) {
+	// 等待期间可能已经被其他请求更新
+	entry := c.lookup(contestID)
+	if entry != nil && entry.fresh(stamp) {
+		return entry.engine, nil
+	}
+
+	// 只有提交记录变化时增量同步，封榜状态变化时需要重新计分
+	if entry != nil && !entry.stale && !entry.engine.frozenChanged(time.Now().Unix()) && stamp.OnlyRunsChanged(entry.stamp) {
+		runs, err := entry.engine.Contest().LoadRuns()
+		if err != nil {
+			return nil, fmt.Errorf("failed to load runs: %w", err)
+		}
+		entry.engine.Sync(runs)
+		c.put(&cacheEntry{contestID: contestID, engine: entry.engine, stamp: stamp, size: entry.engine.size()})
+		return entry.engine, nil
+	}
+
+	contest, err := model.LoadContestConfig(c.store, contestID)
+	if err != nil {
+		return nil, fmt.Errorf("contest not found: %s", err)
+	}
+
+	teams, err := contest.LoadTeams()
+	if err != nil {
+		return nil, fmt.Errorf("failed to load teams: %w", err)
+	}
+
+	runs, err := contest.LoadRuns()
+	if err != nil {
+		return nil, fmt.Errorf("failed to load runs: %w", err)
+	}
+
+	engine := NewEngine(contest, teams, runs)
+	if entry != nil {
+		engine.inheritVersion(entry.engine)
+	}
+	c.put(&cacheEntry{contestID: contestID, engine: engine, stamp: stamp, size: engine.size()})
+	return engine, nil
+}
+
+// fresh 判断缓存的数据是否仍然有效，到达解除封榜时刻后缓存的结果失效
+func (e *cacheEntry) fresh(stamp model.ContestStamp) bool {
+	return !e.stale && e.stamp == stamp && !e.engine.frozenChanged(time.Now().Unix())
+}
+
+// lookup 查找缓存的比赛并标记为最近访问
+func (c *contestCache) lookup(contestID string) *cacheEntry {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	elem, ok := c.entries[contestID]
+	if !ok {
+		return nil
+	}
+	c.lru.MoveToFront(elem)
+	return elem.Value.(*cacheEntry)
+}
+
+// put 放入缓存，超过容量时淘汰最久未访问的比赛
+func (c *contestCache) put(entry *cacheEntry) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if elem, ok := c.entries[entry.contestID]; ok {
+		c.size -= elem.Value.(*cacheEntry).size
+		elem.Value = entry
+		c.lru.MoveToFront(elem)
+	} else {
+		c.entries[entry.contestID] = c.lru.PushFront(entry)
+	}
+	c.size += entry.size
+
+	for c.size > c.capacity && c.lru.Len() > 1 {
+		oldest := c.lru.Back()
+		c.lru.Remove(oldest)
+		delete(c.entries, oldest.Value.(*cacheEntry).contestID)
+		c.size -= oldest.Value.(*cacheEntry).size
+	}
+}
+
+// changedSince 判断缓存的比赛是否到达了解除封榜的时刻需要重新计分，
+// 或者已经被其他请求更新到 version 之后的版本，version 为 0 时只检查前者
+func (c *contestCache) changedSince(contestID string, version uint64) bool {
+	c.mu.Lock()
+	elem, ok := c.entries[contestID]
+	c.mu.Unlock()
+	if !ok {
+		return false
+	}
+
+	engine := elem.Value.(*cacheEntry).engine
+	return engine.frozenChanged(time.Now().Unix()) || (version != 0 && engine.Version() != version)
+}
+
+// invalidate 标记缓存的比赛已失效，下次访问时重新加载
+func (c *contestCache) invalidate(contestID string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if elem, ok := c.entries[contestID]; ok {
+		// 保留旧引擎，重新加载时沿用其版本号
+		entry := *elem.Value.(*cacheEntry)
+		entry.stale = true
+		elem.Value = &entry
+	}
+}
+
+// remove 从缓存中移除比赛
+func (c *contestCache) remove(contestID string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if elem, ok := c.entries[contestID]; ok {
+		c.lru.Remove(elem)
+		delete(c.entries, contestID)
+		c.size -= elem.Value.(*cacheEntry).size
+	}
+}
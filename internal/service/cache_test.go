@@ -0,0 +1,103 @@
+package service
+
+import (
+	"testing"
+
+	"github.com/lllllan02/scoreboard/internal/model"
+	"github.com/lllllan02/scoreboard/internal/model/modeltest"
+)
+
+func TestContestCacheReloadsChangedData(t *testing.T) {
+	contest := modeltest.NewContest()
+	runs := []*model.Run{modeltest.RunAt("1", "t1", 0, 10, "ACCEPTED")}
+	store := model.NewMemoryStore()
+	if err := store.PutContest(contest.ID, contest, modeltest.NewTeams("t1", "t2"), runs); err != nil {
+		t.Fatal(err)
+	}
+
+	cache := newContestCache(store, defaultCacheSize)
+	engine, err := cache.get(contest.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if again, _ := cache.get(contest.ID); again != engine {
+		t.Error("unchanged contest was reloaded")
+	}
+
+	// 只有提交记录变化时增量同步
+	version := engine.Snapshot().Version
+	runs = append(runs, modeltest.RunAt("2", "t2", 1, 20, "ACCEPTED"))
+	if err := store.PutRuns(contest.ID, runs); err != nil {
+		t.Fatal(err)
+	}
+	synced, err := cache.get(contest.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if synced != engine {
+		t.Error("changed runs reloaded the contest instead of syncing")
+	}
+	if snapshot := synced.Snapshot(); snapshot.Version == version || len(snapshot.Runs) != 2 {
+		t.Errorf("got version %d with %d runs after sync, want a new version with 2 runs", snapshot.Version, len(snapshot.Runs))
+	}
+
+	// 其他数据变化时重新加载
+	if err := store.PutContest(contest.ID, contest, modeltest.NewTeams("t1", "t2", "t3"), runs); err != nil {
+		t.Fatal(err)
+	}
+	reloaded, err := cache.get(contest.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if reloaded == engine || len(reloaded.Snapshot().Teams) != 3 {
+		t.Error("changed teams did not reload the contest")
+	}
+
+	// 失效后重新加载，版本号接着旧引擎增长
+	cache.invalidate(contest.ID)
+	again, err := cache.get(contest.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if again == reloaded || again.Version() <= reloaded.Version() {
+		t.Errorf("got version %d after invalidate, want a new engine after version %d", again.Version(), reloaded.Version())
+	}
+}
+
+func TestContestCacheEvictsBySize(t *testing.T) {
+	cache := newContestCache(model.NewMemoryStore(), 5)
+	put := func(contestID string, size int) {
+		cache.put(&cacheEntry{contestID: contestID, engine: NewEngine(modeltest.NewContest(), nil, nil), size: size})
+	}
+	cached := func() []string {
+		var ids []string
+		for elem := cache.lru.Front(); elem != nil; elem = elem.Next() {
+			ids = append(ids, elem.Value.(*cacheEntry).contestID)
+		}
+		return ids
+	}
+
+	put("a", 3)
+	put("b", 2)
+	if got := cached(); len(got) != 2 {
+		t.Fatalf("cached = %v, want [b a]", got)
+	}
+
+	// 超过容量时淘汰最久未访问的比赛
+	cache.lookup("a")
+	put("c", 2)
+	if got := cached(); len(got) != 2 || got[0] != "c" || got[1] != "a" {
+		t.Errorf("cached = %v, want [c a]", got)
+	}
+
+	// 超过容量的比赛也会保留
+	put("d", 10)
+	if got := cached(); len(got) != 1 || got[0] != "d" || cache.size != 10 {
+		t.Errorf("cached = %v with size %d, want [d] with size 10", got, cache.size)
+	}
+
+	cache.remove("d")
+	if got := cached(); len(got) != 0 || cache.size != 0 {
+		t.Errorf("cached = %v with size %d after remove, want none", got, cache.size)
+	}
+}
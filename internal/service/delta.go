@@ -0,0 +1,100 @@
+package service
+
+import (
+	"reflect"
+
+	"github.com/lllllan02/scoreboard/internal/model"
+)
+
+// ResultChange 一支队伍的结果变化
+type ResultChange struct {
+	TeamID  string        `json:"team_id"`
+	OldRank int           `json:"old_rank"` // 之前不在榜单中时为 0
+	NewRank int           `json:"new_rank"`
+	Result  *model.Result `json:"result"`
+}
+
+// ScoreboardDelta 记分板相对某一版本的增量
+type ScoreboardDelta struct {
+	Since       uint64          `json:"since"`
+	Version     uint64          `json:"version"`
+	Full        bool            `json:"full"`              // 基准版本已不再保留，Changes 包含全部队伍
+	Changes     []*ResultChange `json:"changes"`           // 结果发生变化的队伍，按新排名排列
+	Removed     []string        `json:"removed,omitempty"` // 不再出现在榜单中的队伍ID
+	FirstSolves []FirstSolve    `json:"first_solves"`
+	Warnings    []model.Warning `json:"warnings"`
+}
+
+// GetScoreboardDelta 获取记分板相对 since 版本的增量，筛选条件与完整记分板相同
+// since 版本的快照已不再保留时返回全部队伍，并设置 Full
+func (s *ScoreboardService) GetScoreboardDelta(contestID string, filter string, since uint64) (*ScoreboardDelta, error) {
+	engine, err := s.cache.get(contestID)
+	if err != nil {
+		return nil, err
+	}
+
+	current, err := snapshotScoreboard(engine.Snapshot(), filter, 0)
+	if err != nil {
+		return nil, err
+	}
+
+	delta := &ScoreboardDelta{
+		Since:       since,
+		Version:     current.Version,
+		Changes:     []*ResultChange{},
+		FirstSolves: current.FirstSolves,
+		Warnings:    current.Warnings,
+	}
+
+	var previous []*model.Result
+	if since == current.Version {
+		return delta, nil
+	} else if base := engine.SnapshotAt(since); base != nil {
+		board, err := snapshotScoreboard(base, filter, 0)
+		if err != nil {
+			return nil, err
+		}
+		previous = board.Results
+	} else {
+		delta.Full = true
+	}
+
+	delta.Changes, delta.Removed = diffResults(previous, current.Results)
+	return delta, nil
+}
+
+// diffResults 比较两次的结果，返回发生变化的队伍和被移除的队伍
+func diffResults(previous, current []*model.Result) ([]*ResultChange, []string) {
+	oldResults := make(map[string]*model.Result, len(previous))
+	for _, result := range previous {
+		oldResults[result.TeamID] = result
+	}
+
+	changes := []*ResultChange{}
+	for _, result := range current {
+		old, ok := oldResults[result.TeamID]
+		delete(oldResults, result.TeamID)
+		if ok && reflect.DeepEqual(old, result) {
+			continue
+		}
+
+		change := &ResultChange{
+			TeamID:  result.TeamID,
+			NewRank: result.Rank,
+			Result:  result,
+		}
+		if ok {
+			change.OldRank = old.Rank
+		}
+		changes = append(changes, change)
+	}
+
+	var removed []string
+	for _, result := range previous {
+		if _, ok := oldResults[result.TeamID]; ok {
+			removed = append(removed, result.TeamID)
+		}
+	}
+
+	return changes, removed
+}
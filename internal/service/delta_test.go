@@ -0,0 +1,145 @@
+package service
+
+import (
+	"reflect"
+	"sort"
+	"testing"
+
+	"github.com/lllllan02/scoreboard/internal/model"
+	"github.com/lllllan02/scoreboard/internal/model/modeltest"
+)
+
+func TestGetScoreboardDelta(t *testing.T) {
+	base := []*model.Run{
+		modeltest.RunAt("1", "t1", 0, 10, "ACCEPTED"),
+		modeltest.RunAt("2", "t2", 0, 20, "ACCEPTED"),
+		modeltest.RunAt("3", "t3", 1, 30, "WRONG_ANSWER"),
+	}
+
+	tests := []struct {
+		name        string
+		runs        []*model.Run // 第二个版本的提交记录，nil 表示不变
+		filter      string
+		since       func(first, current uint64) uint64
+		wantFull    bool
+		wantChanges []string
+	}{
+		{
+			name:        "up to date",
+			since:       func(first, current uint64) uint64 { return current },
+			wantChanges: []string{},
+		},
+		{
+			name:        "one team solves a problem",
+			runs:        append(base[:3:3], modeltest.RunAt("4", "t3", 1, 40, "ACCEPTED")),
+			since:       func(first, current uint64) uint64 { return first },
+			wantChanges: []string{"t3"},
+		},
+		{
+			name:        "overtaking changes ranks",
+			runs:        append(base[:3:3], modeltest.RunAt("4", "t2", 1, 25, "ACCEPTED")),
+			since:       func(first, current uint64) uint64 { return first },
+			wantChanges: []string{"t1", "t2"},
+		},
+		{
+			name:        "rejudge",
+			runs:        append(base[:3:3], modeltest.RunAt("1", "t1", 0, 10, "WRONG_ANSWER")),
+			since:       func(first, current uint64) uint64 { return first },
+			wantChanges: []string{"t1", "t2", "t3"},
+		},
+		{
+			name:        "filtered",
+			runs:        append(base[:3:3], modeltest.RunAt("4", "t2", 1, 25, "ACCEPTED"), modeltest.RunAt("5", "t3", 2, 50, "ACCEPTED")),
+			filter:      "girls",
+			since:       func(first, current uint64) uint64 { return first },
+			wantChanges: []string{"t3"},
+		},
+		{
+			name:        "unknown version",
+			since:       func(first, current uint64) uint64 { return current + 100 },
+			wantFull:    true,
+			wantChanges: []string{"t1", "t2", "t3"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			contest := modeltest.NewContest()
+			teams := modeltest.NewTeams("t1", "t2", "t3")
+			teams["t3"].IsGirl = true
+
+			store := model.NewMemoryStore()
+			if err := store.PutContest(contest.ID, contest, teams, base); err != nil {
+				t.Fatal(err)
+			}
+			svc := NewScoreboardService(store)
+
+			first, err := svc.GetScoreboardWithFilter(contest.ID, tt.filter, 0)
+			if err != nil {
+				t.Fatal(err)
+			}
+			current := first.Version
+			if tt.runs != nil {
+				if err := store.PutRuns(contest.ID, tt.runs); err != nil {
+					t.Fatal(err)
+				}
+				board, err := svc.GetScoreboardWithFilter(contest.ID, tt.filter, 0)
+				if err != nil {
+					t.Fatal(err)
+				}
+				current = board.Version
+			}
+
+			since := tt.since(first.Version, current)
+			delta, err := svc.GetScoreboardDelta(contest.ID, tt.filter, since)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if delta.Since != since || delta.Version != current {
+				t.Errorf("delta covers %d to %d, want %d to %d", delta.Since, delta.Version, since, current)
+			}
+			if delta.Full != tt.wantFull {
+				t.Errorf("Full = %v, want %v", delta.Full, tt.wantFull)
+			}
+			changed := []string{}
+			for _, change := range delta.Changes {
+				changed = append(changed, change.TeamID)
+			}
+			sort.Strings(changed)
+			if !reflect.DeepEqual(changed, tt.wantChanges) {
+				t.Errorf("changed teams = %v, want %v", changed, tt.wantChanges)
+			}
+			if len(delta.Removed) != 0 {
+				t.Errorf("removed teams = %v, want none", delta.Removed)
+			}
+		})
+	}
+}
+
+func TestDiffResults(t *testing.T) {
+	result := func(teamID string, rank, score int) *model.Result {
+		return &model.Result{TeamID: teamID, Rank: rank, Score: score}
+	}
+
+	previous := []*model.Result{result("t1", 1, 2), result("t2", 2, 1), result("t3", 3, 0)}
+	current := []*model.Result{result("t2", 1, 3), result("t1", 2, 2), result("t4", 3, 0)}
+
+	changes, removed := diffResults(previous, current)
+
+	var got []ResultChange
+	for _, change := range changes {
+		got = append(got, ResultChange{TeamID: change.TeamID, OldRank: change.OldRank, NewRank: change.NewRank})
+	}
+	want := []ResultChange{
+		{TeamID: "t2", OldRank: 2, NewRank: 1},
+		{TeamID: "t1", OldRank: 1, NewRank: 2},
+		{TeamID: "t4", OldRank: 0, NewRank: 3},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("changes = %+v, want %+v", got, want)
+	}
+	if !reflect.DeepEqual(removed, []string{"t3"}) {
+		t.Errorf("removed = %v, want [t3]", removed)
+	}
+}
@@ -0,0 +1,297 @@
+package service
+
+import (
+	"fmt"
+	"sort"
+	"sync"
+
+	"github.com/lllllan02/scoreboard/internal/model"
+)
+
+// Engine 单个比赛的增量计分引擎
+// 引擎在内存中保存比赛的队伍、提交和计分结果，提交逐条应用，重判时只重新计算受影响的题目
+// 引擎的结果统计全部提交（model.LatestTime），读取时通过 Snapshot 获取一致的只读快照
+type Engine struct {
+	mu sync.RWMutex
+
+	contest *model.Contest
+	teams   map[string]*model.Team
+	scorer  *model.Scorer
+	frozen  bool // 创建时记分板是否封榜，解除封榜后需要重新创建引擎
+
+	runs     []*model.Run                    // 全部提交，按（提交时间，提交编号）排序
+	runIndex map[string]*model.Run           // 提交编号 -> 提交
+	cells    map[string]map[int][]*model.Run // 队伍ID -> 题目下标 -> 该题提交（按时间排序）
+	results  map[string]*model.Result        // 队伍ID -> 结果
+	warnings []model.Warning
+
+	version  uint64
+	snapshot *Snapshot   // 当前版本的快照，提交变化后重新生成
+	history  []*Snapshot // 最近生成的快照，用于计算增量
+
+	resolverMu      sync.Mutex
+	resolver        *Resolver // 最近生成的揭晓过程
+	resolverVersion uint64    // resolver 对应的快照版本
+}
+
+// snapshotHistory 每个引擎保留的历史快照数量
+const snapshotHistory = 32
+
+// Snapshot 计分引擎在某一版本的只读快照，调用方不能修改其中的数据
+type Snapshot struct {
+	Version     uint64
+	Contest     *model.Contest
+	Frozen      bool // 快照中的结果是否按封榜状态计算
+	Teams       map[string]*model.Team
+	Runs        []*model.Run    // 按（提交时间，提交编号）排序
+	Results     []*model.Result // 全部队伍，已排名并颁发奖牌
+	FirstSolves []FirstSolve
+	Warnings    []model.Warning
+}
+
+// NewEngine 创建比赛的计分引擎并应用全部提交
+func NewEngine(contest *model.Contest, teams map[string]*model.Team, runs []*model.Run) *Engine {
+	e := &Engine{
+		contest:  contest,
+		teams:    teams,
+		scorer:   contest.NewScorer(model.LatestTime),
+		runIndex: make(map[string]*model.Run),
+		cells:    make(map[string]map[int][]*model.Run),
+		results:  make(map[string]*model.Result, len(teams)),
+	}
+	e.frozen = e.scorer.Frozen()
+	for teamID, team := range teams {
+		e.results[teamID] = e.scorer.NewResult(teamID, team)
+	}
+
+	e.Sync(runs)
+	return e
+}
+
+// Contest 获取引擎对应的比赛
+func (e *Engine) Contest() *model.Contest {
+	return e.contest
+}
+
+// Version 获取引擎当前的版本号
+func (e *Engine) Version() uint64 {
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+
+	return e.version
+}
+
+// size 获取引擎中队伍和提交的数量，用于估算占用的内存
+func (e *Engine) size() int {
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+
+	return len(e.teams) + len(e.runs)
+}
+
+// frozenChanged 判断记分板在 now 时刻的封榜状态是否与引擎计分时不同，即到达了安排的解除封榜时刻
+func (e *Engine) frozenChanged(now int64) bool {
+	return e.contest.IsBoardFrozen(model.LatestTime, now) != e.frozen
+}
+
+// Sync 用完整的提交列表更新引擎，只应用新增、变化和被删除的提交
+func (e *Engine) Sync(runs []*model.Run) {
+	runs, warnings := model.PrepareRuns(runs)
+
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	e.warnings = warnings
+
+	seen := make(map[string]bool, len(runs))
+	occurrences := make(map[string]int)
+	for _, run := range runs {
+		if run.ID == "" {
+			// 没有提交编号的记录按内容生成编号，插入其他记录不会改变已有记录的编号
+			// 内容完全相同的记录按出现次数区分
+			copied := *run
+			copied.ID = fmt.Sprintf("#%s/%d/%d/%s", run.TeamID, run.ProblemID, run.Timestamp, run.Status)
+			occurrences[copied.ID]++
+			if n := occurrences[copied.ID]; n > 1 {
+				copied.ID += fmt.Sprintf("/%d", n)
+			}
+			run = &copied
+		}
+		seen[run.ID] = true
+
+		if old, ok := e.runIndex[run.ID]; ok && sameRun(old, run) {
+			continue
+		}
+		e.applyRun(run)
+	}
+
+	for id, run := range e.runIndex {
+		if !seen[id] {
+			e.removeRun(run)
+		}
+	}
+}
+
+// Snapshot 获取当前版本的只读快照
+func (e *Engine) Snapshot() *Snapshot {
+	e.mu.RLock()
+	snapshot := e.snapshot
+	e.mu.RUnlock()
+	if snapshot != nil {
+		return snapshot
+	}
+
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	if e.snapshot != nil {
+		return e.snapshot
+	}
+
+	results := make([]*model.Result, 0, len(e.results))
+	for _, result := range e.results {
+		results = append(results, result)
+	}
+	results = cloneResults(results)
+
+	// 在全部队伍上计算排名、全场一血并颁发奖牌
+	RecalculateRanking(results, e.contest)
+	firstSolves := MarkGlobalFirstSolves(results, e.contest)
+	AssignMedals(results, e.contest.MedalRanks)
+
+	e.snapshot = &Snapshot{
+		Version:     e.version,
+		Contest:     e.contest,
+		Frozen:      e.frozen,
+		Teams:       e.teams,
+		Runs:        append([]*model.Run(nil), e.runs...),
+		Results:     results,
+		FirstSolves: firstSolves,
+		Warnings:    append([]model.Warning(nil), e.warnings...),
+	}
+
+	e.history = append(e.history, e.snapshot)
+	if len(e.history) > snapshotHistory {
+		e.history = append([]*Snapshot(nil), e.history[len(e.history)-snapshotHistory:]...)
+	}
+	return e.snapshot
+}
+
+// Resolver 获取快照的颁奖仪式揭晓过程，同一版本的快照只生成一次
+func (e *Engine) Resolver(snapshot *Snapshot) *Resolver {
+	e.resolverMu.Lock()
+	defer e.resolverMu.Unlock()
+
+	if e.resolver == nil || e.resolverVersion != snapshot.Version {
+		e.resolver = newSnapshotResolver(snapshot)
+		e.resolverVersion = snapshot.Version
+	}
+	return e.resolver
+}
+
+// SnapshotAt 获取指定版本的历史快照，该版本的快照已不再保留时返回 nil
+func (e *Engine) SnapshotAt(version uint64) *Snapshot {
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+
+	for i := len(e.history) - 1; i >= 0; i-- {
+		if e.history[i].Version == version {
+			return e.history[i]
+		}
+	}
+	return nil
+}
+
+// applyRun 应用一次提交，调用方需持有写锁
+func (e *Engine) applyRun(run *model.Run) {
+	if old, ok := e.runIndex[run.ID]; ok {
+		e.removeRun(old)
+	}
+
+	// 插入全部提交列表
+	i := sort.Search(len(e.runs), func(i int) bool {
+		return model.RunLess(run, e.runs[i])
+	})
+	e.runs = insertRun(e.runs, i, run)
+	e.runIndex[run.ID] = run
+
+	// 插入队伍在该题目上的提交列表
+	result, ok := e.results[run.TeamID]
+	if !ok {
+		e.touch()
+		return
+	}
+	if e.cells[run.TeamID] == nil {
+		e.cells[run.TeamID] = make(map[int][]*model.Run)
+	}
+	cell := e.cells[run.TeamID][run.ProblemID]
+	j := sort.Search(len(cell), func(j int) bool {
+		return model.RunLess(run, cell[j])
+	})
+	cell = insertRun(cell, j, run)
+	e.cells[run.TeamID][run.ProblemID] = cell
+
+	// 新提交在该题目的最后时直接计入，否则重新计算该题目
+	if j == len(cell)-1 {
+		e.scorer.Apply(result, run)
+	} else {
+		e.scorer.RescoreProblem(result, run.ProblemID, cell)
+	}
+	e.touch()
+}
+
+// removeRun 撤销一次提交并重新计算受影响的题目，调用方需持有写锁
+func (e *Engine) removeRun(run *model.Run) {
+	delete(e.runIndex, run.ID)
+	e.runs = deleteRun(e.runs, run)
+
+	if result, ok := e.results[run.TeamID]; ok {
+		cell := deleteRun(e.cells[run.TeamID][run.ProblemID], run)
+		e.cells[run.TeamID][run.ProblemID] = cell
+		e.scorer.RescoreProblem(result, run.ProblemID, cell)
+	}
+	e.touch()
+}
+
+// inheritVersion 让重新加载后创建的引擎版本号接着旧引擎增长，保证同一比赛的版本号单调递增
+func (e *Engine) inheritVersion(old *Engine) {
+	old.mu.RLock()
+	base := old.version
+	old.mu.RUnlock()
+
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	e.version += base + 1
+	e.snapshot = nil
+}
+
+// touch 标记数据已变化，调用方需持有写锁
+func (e *Engine) touch() {
+	e.version++
+	e.snapshot = nil
+}
+
+// sameRun 判断两条提交记录的计分相关字段是否相同
+func sameRun(a, b *model.Run) bool {
+	return a.Status == b.Status && a.TeamID == b.TeamID && a.ProblemID == b.ProblemID &&
+		a.Timestamp == b.Timestamp && a.Score == b.Score && a.Language == b.Language
+}
+
+// insertRun 在位置 i 插入提交
+func insertRun(runs []*model.Run, i int, run *model.Run) []*model.Run {
+	runs = append(runs, nil)
+	copy(runs[i+1:], runs[i:])
+	runs[i] = run
+	return runs
+}
+
+// deleteRun 从列表中删除提交
+func deleteRun(runs []*model.Run, run *model.Run) []*model.Run {
+	for i, r := range runs {
+		if r == run {
+			return append(runs[:i], runs[i+1:]...)
+		}
+	}
+	return runs
+}
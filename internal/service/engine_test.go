@@ -0,0 +1,226 @@
+package service
+
+import (
+	"fmt"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/lllllan02/scoreboard/internal/model"
+	"github.com/lllllan02/scoreboard/internal/model/modeltest"
+)
+
+// copyRuns 复制提交记录，避免引擎之间共享同一份数据
+func copyRuns(contest *model.Contest, runs []*model.Run) []*model.Run {
+	copied := make([]*model.Run, len(runs))
+	for i, run := range runs {
+		r := *run
+		copied[i] = &r
+	}
+	contest.NormalizeRuns(copied)
+	return copied
+}
+
+func TestEngineSyncMatchesRescore(t *testing.T) {
+	base := []*model.Run{
+		modeltest.RunAt("1", "t1", 0, 10, "WRONG_ANSWER"),
+		modeltest.RunAt("2", "t1", 0, 20, "ACCEPTED"),
+		modeltest.RunAt("3", "t2", 0, 25, "ACCEPTED"),
+		modeltest.RunAt("4", "t2", 1, 40, "WRONG_ANSWER"),
+		modeltest.RunAt("5", "t3", 2, 50, "PENDING"),
+		modeltest.RunAt("6", "t2", 1, 60, "ACCEPTED"),
+	}
+	// with 复制 base 并修改
+	with := func(modify func(runs []*model.Run) []*model.Run) []*model.Run {
+		runs := make([]*model.Run, len(base))
+		for i, run := range base {
+			r := *run
+			runs[i] = &r
+		}
+		return modify(runs)
+	}
+	// withoutIDs 去掉全部提交编号
+	withoutIDs := func(runs []*model.Run) []*model.Run {
+		for _, run := range runs {
+			run.ID = ""
+		}
+		return runs
+	}
+
+	tests := []struct {
+		name        string
+		base        []*model.Run
+		runs        []*model.Run
+		wantChanged bool // 同步后版本号是否增加
+	}{
+		{
+			name:        "unchanged",
+			runs:        with(func(runs []*model.Run) []*model.Run { return runs }),
+			wantChanged: false,
+		},
+		{
+			name: "new run",
+			runs: with(func(runs []*model.Run) []*model.Run {
+				return append(runs, modeltest.RunAt("7", "t3", 0, 70, "ACCEPTED"))
+			}),
+			wantChanged: true,
+		},
+		{
+			name: "late run inserted before an accepted run",
+			runs: with(func(runs []*model.Run) []*model.Run {
+				return append(runs, modeltest.RunAt("7", "t1", 0, 15, "WRONG_ANSWER"))
+			}),
+			wantChanged: true,
+		},
+		{
+			name: "rejudge",
+			runs: with(func(runs []*model.Run) []*model.Run {
+				runs[3].Status = "ACCEPTED"
+				return runs
+			}),
+			wantChanged: true,
+		},
+		{
+			name: "pending run judged",
+			runs: with(func(runs []*model.Run) []*model.Run {
+				return append(runs, modeltest.RunAt("5", "t3", 2, 50, "ACCEPTED"))
+			}),
+			wantChanged: true,
+		},
+		{
+			name: "run removed",
+			runs: with(func(runs []*model.Run) []*model.Run {
+				return append(runs[:1], runs[2:]...)
+			}),
+			wantChanged: true,
+		},
+		{
+			name: "run for an unknown team",
+			runs: with(func(runs []*model.Run) []*model.Run {
+				return append(runs, modeltest.RunAt("7", "t9", 0, 30, "ACCEPTED"))
+			}),
+			wantChanged: true,
+		},
+		{
+			name: "run without id inserted first",
+			base: with(withoutIDs),
+			runs: with(func(runs []*model.Run) []*model.Run {
+				return append([]*model.Run{modeltest.RunAt("", "t3", 1, 5, "WRONG_ANSWER")}, withoutIDs(runs)...)
+			}),
+			wantChanged: true,
+		},
+		{
+			name: "duplicate run without id",
+			base: with(withoutIDs),
+			runs: with(func(runs []*model.Run) []*model.Run {
+				runs = withoutIDs(runs)
+				return append(runs, modeltest.RunAt("", "t1", 0, 10, "WRONG_ANSWER"))
+			}),
+			wantChanged: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			contest := modeltest.NewContest()
+			teams := modeltest.NewTeams("t1", "t2", "t3")
+			initial := tt.base
+			if initial == nil {
+				initial = base
+			}
+
+			engine := NewEngine(contest, teams, copyRuns(contest, initial))
+			before := engine.Snapshot().Version
+			engine.Sync(copyRuns(contest, tt.runs))
+			got := engine.Snapshot()
+			want := NewEngine(contest, teams, copyRuns(contest, tt.runs)).Snapshot()
+
+			if changed := got.Version != before; changed != tt.wantChanged {
+				t.Errorf("version changed = %v, want %v", changed, tt.wantChanged)
+			}
+			if len(got.Runs) != len(want.Runs) {
+				t.Errorf("got %d runs, want %d", len(got.Runs), len(want.Runs))
+			}
+			if diff := compareResults(got.Results, want.Results); diff != "" {
+				t.Error(diff)
+			}
+		})
+	}
+}
+
+func TestEngineSyncKeepsRunsWithoutID(t *testing.T) {
+	contest := modeltest.NewContest()
+	runs := []*model.Run{
+		modeltest.RunAt("", "t1", 0, 10, "WRONG_ANSWER"),
+		modeltest.RunAt("", "t1", 0, 10, "WRONG_ANSWER"),
+		modeltest.RunAt("", "t2", 1, 20, "ACCEPTED"),
+	}
+	engine := NewEngine(contest, modeltest.NewTeams("t1", "t2"), copyRuns(contest, runs))
+	before := make(map[string]*model.Run)
+	for id, run := range engine.runIndex {
+		before[id] = run
+	}
+
+	// 在最前面插入一条提交，已有提交的编号不变
+	runs = append([]*model.Run{modeltest.RunAt("", "t2", 0, 5, "WRONG_ANSWER")}, runs...)
+	engine.Sync(copyRuns(contest, runs))
+
+	added := 0
+	for id, run := range engine.runIndex {
+		if old, ok := before[id]; !ok {
+			added++
+		} else if old != run {
+			t.Errorf("run %s was replaced", id)
+		}
+	}
+	if added != 1 || len(engine.runIndex) != len(runs) {
+		t.Errorf("added %d runs with %d in total, want 1 with %d", added, len(engine.runIndex), len(runs))
+	}
+}
+
+func TestEngineFrozenUntilScheduledUnfreeze(t *testing.T) {
+	contest := modeltest.NewContest()
+	contest.FrozenTime = 3600
+	now := time.Now().Unix()
+	contest.UnfreezeTime = now + 600
+
+	engine := NewEngine(contest, modeltest.NewTeams("t1"), nil)
+	if !engine.Snapshot().Frozen {
+		t.Error("snapshot is not frozen before the scheduled unfreeze time")
+	}
+	if engine.frozenChanged(now) {
+		t.Error("frozenChanged() = true before the scheduled unfreeze time")
+	}
+	if !engine.frozenChanged(now + 600) {
+		t.Error("frozenChanged() = false at the scheduled unfreeze time")
+	}
+}
+
+// compareResults 按队伍比较两组结果的排名、成绩和各题结果，相同时返回空字符串
+func compareResults(got, want []*model.Result) string {
+	wantByTeam := make(map[string]*model.Result, len(want))
+	for _, result := range want {
+		wantByTeam[result.TeamID] = result
+	}
+	if len(got) != len(want) {
+		return fmt.Sprintf("got %d results, want %d", len(got), len(want))
+	}
+
+	for _, g := range got {
+		w, ok := wantByTeam[g.TeamID]
+		if !ok {
+			return fmt.Sprintf("unexpected team %s", g.TeamID)
+		}
+		if g.Rank != w.Rank || g.Score != w.Score || g.TotalTime != w.TotalTime ||
+			fmt.Sprint(g.SolvedTimes) != fmt.Sprint(w.SolvedTimes) {
+			return fmt.Sprintf("team %s: got rank %d score %d time %d solved %v, want rank %d score %d time %d solved %v",
+				g.TeamID, g.Rank, g.Score, g.TotalTime, g.SolvedTimes, w.Rank, w.Score, w.TotalTime, w.SolvedTimes)
+		}
+		for problemID, problem := range w.ProblemResults {
+			if !reflect.DeepEqual(g.ProblemResults[problemID], problem) {
+				return fmt.Sprintf("team %s problem %s: got %+v, want %+v", g.TeamID, problemID, g.ProblemResults[problemID], problem)
+			}
+		}
+	}
+	return ""
+}
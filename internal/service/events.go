@@ -0,0 +1,86 @@
+package service
+
+import (
+	"sync"
+	"time"
+)
+
+// EventType 事件类型
+type EventType string
+
+const (
+	EventContestUpdated EventType = "contest_updated" // 比赛数据重新加载
+	EventContestRemoved EventType = "contest_removed" // 比赛数据被删除
+)
+
+// subscriberBuffer 每个订阅者的事件缓冲区大小
+const subscriberBuffer = 16
+
+// Event 比赛数据变化事件
+type Event struct {
+	Type      EventType `json:"type"`
+	ContestID string    `json:"contest_id"`
+	Version   uint64    `json:"version"` // 变化后计分快照的版本
+	Time      time.Time `json:"time"`
+}
+
+// subscription 事件订阅
+type subscription struct {
+	contestID string // 为空时接收所有比赛的事件
+	ch        chan Event
+}
+
+// EventBus 进程内的事件总线，监听器发布事件，HTTP 层订阅后推送给客户端
+// 发布不会阻塞：订阅者的缓冲区满时丢弃该事件，订阅者应以最新快照为准
+type EventBus struct {
+	mu   sync.RWMutex
+	subs map[*subscription]struct{}
+}
+
+// NewEventBus 创建事件总线
+func NewEventBus() *EventBus {
+	return &EventBus{
+		subs: make(map[*subscription]struct{}),
+	}
+}
+
+// Subscribe 订阅比赛的事件，contestID 为空时订阅所有比赛
+// 返回接收事件的通道和取消订阅的函数，取消订阅后通道被关闭
+func (b *EventBus) Subscribe(contestID string) (<-chan Event, func()) {
+	sub := &subscription{
+		contestID: contestID,
+		ch:        make(chan Event, subscriberBuffer),
+	}
+
+	b.mu.Lock()
+	b.subs[sub] = struct{}{}
+	b.mu.Unlock()
+
+	var once sync.Once
+	cancel := func() {
+		once.Do(func() {
+			b.mu.Lock()
+			delete(b.subs, sub)
+			b.mu.Unlock()
+			close(sub.ch)
+		})
+	}
+	return sub.ch, cancel
+}
+
+// Publish 向订阅了该比赛的订阅者发布事件
+func (b *EventBus) Publish(event Event) {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+
+	for sub := range b.subs {
+		if sub.contestID != "" && sub.contestID != event.ContestID {
+			continue
+		}
+		select {
+		case sub.ch <- event:
+		default:
+			// 订阅者处理不过来，丢弃事件
+		}
+	}
+}
@@ -0,0 +1,279 @@
+package service
+
+import (
+	"context"
+	"sync"
+
+	"github.com/lllllan02/scoreboard/internal/model"
+)
+
+// FeedMessageType 推送消息类型
+type FeedMessageType string
+
+const (
+	FeedSync       FeedMessageType = "sync"        // 当前的序号和快照版本，无法续传时客户端应重新获取完整记分板
+	FeedSubmission FeedMessageType = "submission"  // 新提交
+	FeedVerdict    FeedMessageType = "verdict"     // 提交的评测结果变化，包括重判
+	FeedRank       FeedMessageType = "rank"        // 队伍的排名或成绩变化
+	FeedFirstSolve FeedMessageType = "first_solve" // 新的全场一血
+)
+
+const (
+	// feedHistory 每个比赛保留的历史消息数量，用于断线续传
+	feedHistory = 1024
+	// feedSubscriberBuffer 每个订阅者的消息缓冲区大小
+	feedSubscriberBuffer = 256
+)
+
+// FeedMessage 推送给客户端的一条消息，ID 在同一比赛内递增
+type FeedMessage struct {
+	ID      uint64          `json:"id"`
+	Type    FeedMessageType `json:"type"`
+	Version uint64          `json:"version"` // 产生消息的计分快照版本
+	Data    interface{}     `json:"data,omitempty"`
+}
+
+// RankChange 队伍排名或成绩的变化
+type RankChange struct {
+	TeamID       string `json:"team_id"`
+	TeamName     string `json:"team_name"`
+	OldRank      int    `json:"old_rank"` // 新出现的队伍为 0
+	NewRank      int    `json:"new_rank"`
+	OfficialRank int    `json:"official_rank"`
+	Score        int    `json:"score"`
+	TotalTime    int64  `json:"total_time"`
+}
+
+// contestFeed 单个比赛的消息流
+type contestFeed struct {
+	seq     uint64        // 最后一条消息的 ID
+	base    uint64        // 历史记录之前的最后一条消息的 ID，不早于它的消息都可以续传
+	last    *Snapshot     // 上一次生成消息时的快照
+	history []FeedMessage // 最近的消息，按 ID 递增
+	subs    map[chan FeedMessage]struct{}
+}
+
+// Feed 把比赛更新事件转换成提交、评测结果、排名和一血消息，推送给订阅的客户端
+// 每个比赛的消息只在有客户端订阅时生成，消息 ID 用于客户端断线后续传
+type Feed struct {
+	svc *ScoreboardService
+	seq uint64 // 所有比赛共用的消息 ID，比赛的订阅全部取消后重新订阅时 ID 不会重复
+
+	mu       sync.Mutex
+	contests map[string]*contestFeed
+}
+
+// NewFeed 创建消息流
+func NewFeed(svc *ScoreboardService) *Feed {
+	return &Feed{
+		svc:      svc,
+		contests: make(map[string]*contestFeed),
+	}
+}
+
+// Run 从事件总线接收比赛更新事件并生成消息，直到 ctx 被取消
+func (f *Feed) Run(ctx context.Context) {
+	events, cancel := f.svc.Events().Subscribe("")
+	defer cancel()
+
+	for {
+		select {
+		case <-ctx.Done():
+			f.closeAll()
+			return
+		case event, ok := <-events:
+			if !ok {
+				return
+			}
+			if event.Type == EventContestUpdated {
+				f.update(event.ContestID)
+			}
+		}
+	}
+}
+
+// Subscribe 订阅比赛的消息，lastEventID 为客户端收到的最后一条消息的 ID，0 表示新连接
+// 返回需要补发的消息、接收后续消息的通道和取消订阅的函数
+// 无法续传时补发的消息只有一条 FeedSync，订阅者处理不过来时通道会被关闭，客户端应重新连接
+func (f *Feed) Subscribe(contestID string, lastEventID uint64) ([]FeedMessage, <-chan FeedMessage, func(), error) {
+	snapshot, err := f.svc.getSnapshot(contestID)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	cf, ok := f.contests[contestID]
+	if !ok {
+		// 新的消息流从一个未使用过的 ID 开始，之前的 ID 都无法续传
+		f.seq++
+		cf = &contestFeed{
+			seq:  f.seq,
+			base: f.seq,
+			last: snapshot,
+			subs: make(map[chan FeedMessage]struct{}),
+		}
+		f.contests[contestID] = cf
+	}
+
+	ch := make(chan FeedMessage, feedSubscriberBuffer)
+	cf.subs[ch] = struct{}{}
+
+	// 最后一个订阅者离开后删除比赛的消息流，包括处理不过来被断开的订阅者
+	cancel := func() {
+		f.mu.Lock()
+		defer f.mu.Unlock()
+
+		if _, ok := cf.subs[ch]; ok {
+			delete(cf.subs, ch)
+			close(ch)
+		}
+		if len(cf.subs) == 0 && f.contests[contestID] == cf {
+			delete(f.contests, contestID)
+		}
+	}
+
+	return cf.backlog(lastEventID), ch, cancel, nil
+}
+
+// backlog 获取 lastEventID 之后的消息，调用方需持有锁
+func (cf *contestFeed) backlog(lastEventID uint64) []FeedMessage {
+	sync := FeedMessage{ID: cf.seq, Type: FeedSync, Version: cf.last.Version}
+	if lastEventID == 0 || lastEventID > cf.seq {
+		return []FeedMessage{sync}
+	}
+
+	// 需要的消息已经不在历史记录中，或者不属于当前的消息流
+	if lastEventID < cf.base {
+		return []FeedMessage{sync}
+	}
+
+	var messages []FeedMessage
+	for _, msg := range cf.history {
+		if msg.ID > lastEventID {
+			messages = append(messages, msg)
+		}
+	}
+	return messages
+}
+
+// update 比赛数据变化后生成新消息并推送给订阅者
+func (f *Feed) update(contestID string) {
+	f.mu.Lock()
+	cf, ok := f.contests[contestID]
+	f.mu.Unlock()
+	if !ok {
+		return
+	}
+
+	snapshot, err := f.svc.getSnapshot(contestID)
+	if err != nil {
+		return
+	}
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if snapshot.Version == cf.last.Version {
+		return
+	}
+
+	for _, msg := range diffSnapshots(cf.last, snapshot) {
+		f.seq++
+		cf.seq = f.seq
+		msg.ID = cf.seq
+		cf.history = append(cf.history, msg)
+
+		for ch := range cf.subs {
+			select {
+			case ch <- msg:
+			default:
+				// 订阅者处理不过来，断开连接让客户端续传
+				delete(cf.subs, ch)
+				close(ch)
+			}
+		}
+	}
+	if len(cf.history) > feedHistory {
+		cf.base = cf.history[len(cf.history)-feedHistory-1].ID
+		cf.history = append([]FeedMessage(nil), cf.history[len(cf.history)-feedHistory:]...)
+	}
+	cf.last = snapshot
+}
+
+// closeAll 关闭所有订阅者的通道
+func (f *Feed) closeAll() {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	for _, cf := range f.contests {
+		for ch := range cf.subs {
+			delete(cf.subs, ch)
+			close(ch)
+		}
+	}
+}
+
+// diffSnapshots 比较两个快照，生成提交、评测结果、排名和一血消息
+func diffSnapshots(old, cur *Snapshot) []FeedMessage {
+	var messages []FeedMessage
+
+	// 提交和评测结果，封榜期间的提交不公开评测结果
+	oldRuns := make(map[string]*model.Run, len(old.Runs))
+	for _, run := range old.Runs {
+		oldRuns[run.ID] = run
+	}
+	for _, run := range cur.Runs {
+		record := newSubmissionRecord(cur, run)
+		if record == nil {
+			continue
+		}
+
+		prev, ok := oldRuns[run.ID]
+		switch {
+		case !ok:
+			messages = append(messages, FeedMessage{Type: FeedSubmission, Version: cur.Version, Data: record})
+		case visibleVerdict(old, prev) != visibleVerdict(cur, run):
+			messages = append(messages, FeedMessage{Type: FeedVerdict, Version: cur.Version, Data: record})
+		}
+	}
+
+	// 排名和成绩
+	oldResults := make(map[string]*model.Result, len(old.Results))
+	for _, result := range old.Results {
+		oldResults[result.TeamID] = result
+	}
+	for _, result := range cur.Results {
+		prev, ok := oldResults[result.TeamID]
+		if ok && prev.Rank == result.Rank && prev.Score == result.Score && prev.TotalTime == result.TotalTime {
+			continue
+		}
+
+		change := &RankChange{
+			TeamID:       result.TeamID,
+			NewRank:      result.Rank,
+			OfficialRank: result.OfficialRank,
+			Score:        result.Score,
+			TotalTime:    result.TotalTime,
+		}
+		if result.Team != nil {
+			change.TeamName = result.Team.Name
+		}
+		if ok {
+			change.OldRank = prev.Rank
+		}
+		messages = append(messages, FeedMessage{Type: FeedRank, Version: cur.Version, Data: change})
+	}
+
+	// 全场一血
+	for i := range cur.FirstSolves {
+		firstSolve := cur.FirstSolves[i]
+		if isFirstSolve(old.FirstSolves, firstSolve.ProblemID, firstSolve.TeamID) {
+			continue
+		}
+		messages = append(messages, FeedMessage{Type: FeedFirstSolve, Version: cur.Version, Data: &firstSolve})
+	}
+
+	return messages
+}
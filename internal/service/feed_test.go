@@ -0,0 +1,97 @@
+package service
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/lllllan02/scoreboard/internal/model"
+	"github.com/lllllan02/scoreboard/internal/model/modeltest"
+)
+
+// receiveAll 获取通道中已经推送的全部消息
+func receiveAll(ch <-chan FeedMessage) []FeedMessage {
+	var messages []FeedMessage
+	for {
+		select {
+		case msg := <-ch:
+			messages = append(messages, msg)
+		default:
+			return messages
+		}
+	}
+}
+
+// messageIDs 获取消息的 ID 列表
+func messageIDs(messages []FeedMessage) []uint64 {
+	var ids []uint64
+	for _, msg := range messages {
+		ids = append(ids, msg.ID)
+	}
+	return ids
+}
+
+func TestFeedResumesFromLastEventID(t *testing.T) {
+	contest := modeltest.NewContest()
+	runs := []*model.Run{modeltest.RunAt("1", "t1", 0, 10, "ACCEPTED")}
+	store := model.NewMemoryStore()
+	if err := store.PutContest(contest.ID, contest, modeltest.NewTeams("t1", "t2"), runs); err != nil {
+		t.Fatal(err)
+	}
+
+	feed := NewFeed(NewScoreboardService(store))
+	backlog, ch, cancel, err := feed.Subscribe(contest.ID, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(backlog) != 1 || backlog[0].Type != FeedSync {
+		t.Fatalf("backlog = %+v for a new connection, want a single sync message", backlog)
+	}
+	syncID := backlog[0].ID
+
+	// 新提交产生提交和排名消息
+	runs = append(runs, modeltest.RunAt("2", "t2", 1, 20, "ACCEPTED"))
+	if err := store.PutRuns(contest.ID, runs); err != nil {
+		t.Fatal(err)
+	}
+	feed.update(contest.ID)
+	pushed := receiveAll(ch)
+	if len(pushed) < 2 || pushed[0].Type != FeedSubmission {
+		t.Fatalf("pushed %+v, want a submission followed by rank changes", pushed)
+	}
+
+	tests := []struct {
+		name        string
+		lastEventID uint64
+		want        []uint64
+	}{
+		{"resume after the sync message", syncID, messageIDs(pushed)},
+		{"resume in the middle", pushed[0].ID, messageIDs(pushed[1:])},
+		{"up to date", pushed[len(pushed)-1].ID, nil},
+		{"unknown id", pushed[len(pushed)-1].ID + 100, []uint64{pushed[len(pushed)-1].ID}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			backlog, _, cancel, err := feed.Subscribe(contest.ID, tt.lastEventID)
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer cancel()
+
+			if got := messageIDs(backlog); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("backlog ids = %v, want %v", got, tt.want)
+			}
+		})
+	}
+
+	// 最后一个订阅者离开后消息流被删除，之前的 ID 无法续传
+	cancel()
+	backlog, _, cancel, err = feed.Subscribe(contest.ID, pushed[0].ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer cancel()
+	if len(backlog) != 1 || backlog[0].Type != FeedSync || backlog[0].ID <= pushed[len(pushed)-1].ID {
+		t.Errorf("backlog = %+v after the feed was dropped, want a sync message with a new id", backlog)
+	}
+}
@@ -0,0 +1,142 @@
+package service
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/lllllan02/scoreboard/internal/model"
+)
+
+// 学校排名方式
+const (
+	OrganizationRankBest = "best" // 按学校最好队伍的排名
+	OrganizationRankTopK = "top"  // 按学校前 K 支队伍的总成绩
+)
+
+// OrganizationOptions 学校排名选项
+type OrganizationOptions struct {
+	Mode              string // 排名方式，默认 OrganizationRankBest
+	TopK              int    // Mode 为 OrganizationRankTopK 时计入排名的队伍数
+	ExcludeUnofficial bool   // 是否排除打星队伍
+}
+
+// OrganizationTeam 学校排名中的队伍信息
+type OrganizationTeam struct {
+	TeamID       string `json:"team_id"`
+	Name         string `json:"name"`
+	Rank         int    `json:"rank"`
+	OfficialRank int    `json:"official_rank"`
+	Score        int    `json:"score"`
+	TotalTime    int64  `json:"total_time"`
+	Medal        string `json:"medal,omitempty"`
+}
+
+// OrganizationResult 学校排名结果
+type OrganizationResult struct {
+	Rank         int                 `json:"rank"`
+	Organization string              `json:"organization"`
+	TeamCount    int                 `json:"team_count"`
+	SolvedCount  int                 `json:"solved_count"` // 所有队伍通过的题目总数
+	Score        int                 `json:"score"`        // 计入排名的队伍得分之和
+	TotalTime    int64               `json:"total_time"`   // 计入排名的队伍罚时之和
+	BestRank     int                 `json:"best_rank"`    // 最好队伍在榜单中的位置
+	Teams        []*OrganizationTeam `json:"teams"`        // 按排名排序
+}
+
+// RankOrganizations 计算学校排名
+// results 需要已经按排名排序，没有学校信息的队伍不参与
+func RankOrganizations(results []*model.Result, opts OrganizationOptions) []*OrganizationResult {
+	topK := 1
+	if opts.Mode == OrganizationRankTopK && opts.TopK > 0 {
+		topK = opts.TopK
+	}
+
+	// 按学校汇总队伍，队伍保持排名顺序
+	var organizations []*OrganizationResult
+	organizationMap := make(map[string]*OrganizationResult)
+	for _, result := range results {
+		school := result.Team.Organization
+		if school == "" || (opts.ExcludeUnofficial && !result.Team.IsOfficial()) {
+			continue
+		}
+
+		organization, ok := organizationMap[school]
+		if !ok {
+			organization = &OrganizationResult{
+				Organization: school,
+				BestRank:     result.Rank,
+			}
+			organizationMap[school] = organization
+			organizations = append(organizations, organization)
+		}
+
+		if organization.TeamCount < topK {
+			organization.Score += result.Score
+			organization.TotalTime += result.TotalTime
+		}
+		organization.TeamCount++
+		for _, problem := range result.ProblemResults {
+			if problem.Solved {
+				organization.SolvedCount++
+			}
+		}
+		organization.Teams = append(organization.Teams, &OrganizationTeam{
+			TeamID:       result.TeamID,
+			Name:         result.Team.Name,
+			Rank:         result.Rank,
+			OfficialRank: result.OfficialRank,
+			Score:        result.Score,
+			TotalTime:    result.TotalTime,
+			Medal:        result.Medal,
+		})
+	}
+
+	// 按最好队伍排名时学校已经按首次出现的顺序排列，只需处理并列
+	compare := func(a, b *OrganizationResult) int {
+		return a.BestRank - b.BestRank
+	}
+	if opts.Mode == OrganizationRankTopK {
+		compare = func(a, b *OrganizationResult) int {
+			if a.Score != b.Score {
+				return b.Score - a.Score
+			}
+			if a.TotalTime != b.TotalTime {
+				if a.TotalTime < b.TotalTime {
+					return -1
+				}
+				return 1
+			}
+			return a.BestRank - b.BestRank
+		}
+		sort.SliceStable(organizations, func(i, j int) bool {
+			return compare(organizations[i], organizations[j]) < 0
+		})
+	}
+
+	for i, organization := range organizations {
+		organization.Rank = i + 1
+		if i > 0 && compare(organizations[i-1], organization) == 0 {
+			organization.Rank = organizations[i-1].Rank
+		}
+	}
+
+	return organizations
+}
+
+// GetOrganizationRanking 获取比赛的学校排名
+func (s *ScoreboardService) GetOrganizationRanking(contestID string, filter string, asOf int64, opts OrganizationOptions) ([]*OrganizationResult, *model.Contest, error) {
+	switch opts.Mode {
+	case "":
+		opts.Mode = OrganizationRankBest
+	case OrganizationRankBest, OrganizationRankTopK:
+	default:
+		return nil, nil, fmt.Errorf("invalid organization rank mode: %s", opts.Mode)
+	}
+
+	scoreboard, err := s.GetScoreboardWithFilter(contestID, filter, asOf)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return RankOrganizations(scoreboard.Results, opts), scoreboard.Contest, nil
+}
@@ -0,0 +1,88 @@
+package service
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/lllllan02/scoreboard/internal/model"
+	"github.com/lllllan02/scoreboard/internal/model/modeltest"
+)
+
+// newOrganizationResults 创建已排名的结果：School Y 和 School Z 的最好队伍并列第二，
+// School W 只有打星队伍，最后一支队伍没有学校信息
+func newOrganizationResults() []*model.Result {
+	result := func(teamID, organization string, rank, score int, totalTime int64, groups ...string) *model.Result {
+		return &model.Result{
+			TeamID:         teamID,
+			Team:           &model.Team{ID: teamID, Name: "Team " + teamID, Organization: organization, Groups: groups},
+			Rank:           rank,
+			Score:          score,
+			TotalTime:      totalTime,
+			ProblemResults: map[string]*model.ProblemResult{},
+		}
+	}
+	return []*model.Result{
+		result("t1", "School X", 1, 3, 100),
+		result("t2", "School Y", 2, 2, 50),
+		result("t3", "School Z", 2, 2, 50),
+		result("t4", "School X", 4, 2, 80),
+		result("t5", "School W", 5, 1, 10, "unofficial"),
+		result("t6", "", 6, 0, 0),
+	}
+}
+
+func TestRankOrganizations(t *testing.T) {
+	type organizationRank struct {
+		Organization string
+		Rank         int
+		Score        int
+	}
+
+	tests := []struct {
+		name string
+		opts OrganizationOptions
+		want []organizationRank
+	}{
+		{
+			name: "best team",
+			opts: OrganizationOptions{Mode: OrganizationRankBest},
+			want: []organizationRank{{"School X", 1, 3}, {"School Y", 2, 2}, {"School Z", 2, 2}, {"School W", 4, 1}},
+		},
+		{
+			name: "best official team",
+			opts: OrganizationOptions{Mode: OrganizationRankBest, ExcludeUnofficial: true},
+			want: []organizationRank{{"School X", 1, 3}, {"School Y", 2, 2}, {"School Z", 2, 2}},
+		},
+		{
+			name: "top teams",
+			opts: OrganizationOptions{Mode: OrganizationRankTopK, TopK: 2},
+			want: []organizationRank{{"School X", 1, 5}, {"School Y", 2, 2}, {"School Z", 2, 2}, {"School W", 4, 1}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got []organizationRank
+			for _, organization := range RankOrganizations(newOrganizationResults(), tt.opts) {
+				got = append(got, organizationRank{organization.Organization, organization.Rank, organization.Score})
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("organizations = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRecalculateRankingSchoolRanks(t *testing.T) {
+	results := newOrganizationResults()
+	RecalculateRanking(results, modeltest.NewContest())
+
+	got := make(map[string]int)
+	for _, result := range results {
+		got[result.TeamID] = result.SchoolRank
+	}
+	want := map[string]int{"t1": 1, "t2": 2, "t3": 2, "t4": 1, "t5": 0, "t6": 0}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("school ranks = %v, want %v", got, want)
+	}
+}
@@ -0,0 +1,198 @@
+package service
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/lllllan02/scoreboard/internal/model"
+)
+
+// 揭晓步骤类型
+const (
+	ResolverStepReveal = "reveal" // 揭晓一道封榜题目
+	ResolverStepAward  = "award"  // 队伍最终名次确定并颁发奖牌
+)
+
+// ResolverStep 颁奖仪式揭晓过程中的一步
+type ResolverStep struct {
+	Index     int    `json:"index"` // 从 1 开始的步骤序号
+	Type      string `json:"type"`
+	TeamID    string `json:"team_id"`
+	TeamName  string `json:"team_name"`
+	ProblemID string `json:"problem_id,omitempty"`
+	Solved    bool   `json:"solved,omitempty"`
+	OldRank   int    `json:"old_rank"`
+	NewRank   int    `json:"new_rank"`
+	Medal     string `json:"medal,omitempty"`
+}
+
+// Resolver 颁奖仪式揭晓器
+// 从封榜时的记分板出发，自下而上每次揭晓一道封榜题目，直到得到最终记分板
+type Resolver struct {
+	Steps []ResolverStep
+
+	contest *model.Contest
+	frozen  []*model.Result          // 封榜记分板，已按排名排序
+	final   map[string]*model.Result // 最终结果，按队伍ID索引
+}
+
+// NewResolver 根据比赛的封榜结果和最终结果生成揭晓过程
+func NewResolver(contest *model.Contest, frozen, final []*model.Result) *Resolver {
+	r := &Resolver{
+		contest: contest,
+		frozen:  frozen,
+		final:   make(map[string]*model.Result, len(final)),
+	}
+
+	// 最终奖牌以最终记分板为准
+	RecalculateRanking(final, contest)
+	AssignMedals(final, contest.MedalRanks)
+	for _, result := range final {
+		r.final[result.TeamID] = result
+	}
+
+	RecalculateRanking(r.frozen, contest)
+	r.buildSteps()
+
+	return r
+}
+
+// newSnapshotResolver 根据计分快照生成揭晓过程
+// 以比赛结束时仍处于封榜状态的记分板为起点，以解除封榜后的最终记分板为终点
+func newSnapshotResolver(snapshot *Snapshot) *Resolver {
+	contest := snapshot.Contest
+
+	// 封榜记分板：不考虑是否已经解除封榜
+	frozenContest := *contest
+	frozenContest.UnfreezeTime = 0
+	frozen := frozenContest.ScoreRuns(snapshot.Teams, snapshot.Runs, contest.EndTime)
+
+	// 最终记分板：比赛结束时即解除封榜
+	finalContest := *contest
+	finalContest.UnfreezeTime = contest.EndTime
+	final := finalContest.ScoreRuns(snapshot.Teams, snapshot.Runs, contest.EndTime)
+
+	return NewResolver(contest, frozen, final)
+}
+
+// buildSteps 模拟揭晓过程，生成全部步骤
+func (r *Resolver) buildSteps() {
+	board := cloneResults(r.frozen)
+	revealed := make(map[string]map[string]bool)
+
+	// 从最后一名开始，当前位置的队伍没有待揭晓的题目时，其名次确定，位置上移
+	for i := len(board) - 1; i >= 0; {
+		result := board[i]
+		problemID := r.nextPending(result, revealed[result.TeamID])
+
+		if problemID == "" {
+			// 名次确定，获得奖牌时记录颁奖
+			if medal := r.final[result.TeamID].Medal; medal != "" {
+				r.Steps = append(r.Steps, ResolverStep{
+					Index:    len(r.Steps) + 1,
+					Type:     ResolverStepAward,
+					TeamID:   result.TeamID,
+					TeamName: result.Team.Name,
+					OldRank:  result.Rank,
+					NewRank:  result.Rank,
+					Medal:    medal,
+				})
+			}
+			i--
+			continue
+		}
+
+		// 揭晓该题目并重新排名
+		if revealed[result.TeamID] == nil {
+			revealed[result.TeamID] = make(map[string]bool)
+		}
+		revealed[result.TeamID][problemID] = true
+
+		oldRank := result.Rank
+		r.reveal(result, problemID)
+		RecalculateRanking(board, r.contest)
+
+		r.Steps = append(r.Steps, ResolverStep{
+			Index:     len(r.Steps) + 1,
+			Type:      ResolverStepReveal,
+			TeamID:    result.TeamID,
+			TeamName:  result.Team.Name,
+			ProblemID: problemID,
+			Solved:    result.ProblemResults[problemID].Solved,
+			OldRank:   oldRank,
+			NewRank:   result.Rank,
+		})
+	}
+}
+
+// nextPending 按题目顺序获取队伍下一道待揭晓的封榜题目，没有时返回空字符串
+func (r *Resolver) nextPending(result *model.Result, revealed map[string]bool) string {
+	for _, problemID := range r.contest.ProblemIDs {
+		if problem, ok := result.ProblemResults[problemID]; ok && problem.IsFrozen && !revealed[problemID] {
+			return problemID
+		}
+	}
+	return ""
+}
+
+// reveal 用最终结果替换队伍的题目结果，并重新计算队伍总成绩
+func (r *Resolver) reveal(result *model.Result, problemID string) {
+	problem := *r.final[result.TeamID].ProblemResults[problemID]
+	result.ProblemResults[problemID] = &problem
+
+	result.Score = 0
+	result.TotalTime = 0
+	result.SolvedTimes = result.SolvedTimes[:0]
+	for _, problem := range result.ProblemResults {
+		result.Score += problem.Score
+		if problem.Solved {
+			result.TotalTime += problem.PenaltyTime
+			result.SolvedTimes = append(result.SolvedTimes, problem.SolvedTime)
+		}
+	}
+	sort.Slice(result.SolvedTimes, func(i, j int) bool {
+		return result.SolvedTimes[i] < result.SolvedTimes[j]
+	})
+}
+
+// BoardAt 获取执行完前 cursor 步之后的记分板
+func (r *Resolver) BoardAt(cursor int) ([]*model.Result, error) {
+	if cursor < 0 || cursor > len(r.Steps) {
+		return nil, fmt.Errorf("cursor out of range: %d", cursor)
+	}
+
+	board := cloneResults(r.frozen)
+	resultsMap := make(map[string]*model.Result, len(board))
+	for _, result := range board {
+		resultsMap[result.TeamID] = result
+	}
+
+	for _, step := range r.Steps[:cursor] {
+		result := resultsMap[step.TeamID]
+		switch step.Type {
+		case ResolverStepReveal:
+			r.reveal(result, step.ProblemID)
+		case ResolverStepAward:
+			result.Medal = step.Medal
+		}
+	}
+	RecalculateRanking(board, r.contest)
+
+	return board, nil
+}
+
+// cloneResults 深拷贝结果列表，队伍信息共享
+func cloneResults(results []*model.Result) []*model.Result {
+	cloned := make([]*model.Result, len(results))
+	for i, result := range results {
+		c := *result
+		c.SolvedTimes = append([]int64(nil), result.SolvedTimes...)
+		c.ProblemResults = make(map[string]*model.ProblemResult, len(result.ProblemResults))
+		for problemID, problem := range result.ProblemResults {
+			p := *problem
+			c.ProblemResults[problemID] = &p
+		}
+		cloned[i] = &c
+	}
+	return cloned
+}
@@ -0,0 +1,159 @@
+package service
+
+import (
+	"reflect"
+	"sort"
+	"testing"
+
+	"github.com/lllllan02/scoreboard/internal/model"
+	"github.com/lllllan02/scoreboard/internal/model/modeltest"
+)
+
+// newResolverResult 根据题目结果创建队伍结果，按 ICPC 规则汇总解题数和罚时
+func newResolverResult(team *model.Team, problems ...*model.ProblemResult) *model.Result {
+	result := &model.Result{TeamID: team.ID, Team: team, ProblemResults: make(map[string]*model.ProblemResult)}
+	for _, problemID := range []string{"A", "B", "C"} {
+		result.ProblemResults[problemID] = &model.ProblemResult{ProblemID: problemID}
+	}
+	for _, problem := range problems {
+		result.ProblemResults[problem.ProblemID] = problem
+		result.Score += problem.Score
+		if problem.Solved {
+			result.TotalTime += problem.PenaltyTime
+			result.SolvedTimes = append(result.SolvedTimes, problem.SolvedTime)
+		}
+	}
+	sort.Slice(result.SolvedTimes, func(i, j int) bool {
+		return result.SolvedTimes[i] < result.SolvedTimes[j]
+	})
+	return result
+}
+
+// solved 创建 minute 分钟通过的题目结果
+func solved(problemID string, minute int64) *model.ProblemResult {
+	return &model.ProblemResult{ProblemID: problemID, Solved: true, Score: 1, SolvedTime: minute, PenaltyTime: minute}
+}
+
+// newTestResolver 创建三支队伍的揭晓过程：t2 封榜后通过 B 题升到第一，t3 封榜后的提交没有通过
+func newTestResolver() *Resolver {
+	contest := modeltest.NewContest()
+	contest.FrozenTime = 3600
+	contest.MedalRanks = map[string]map[string]int{"official": {"gold": 1, "silver": 1, "bronze": 1}}
+	teams := modeltest.NewTeams("t1", "t2", "t3")
+
+	frozen := []*model.Result{
+		newResolverResult(teams["t1"], solved("A", 10)),
+		newResolverResult(teams["t2"], solved("A", 20), &model.ProblemResult{ProblemID: "B", IsFrozen: true, PendingAttempts: 1}),
+		newResolverResult(teams["t3"], &model.ProblemResult{ProblemID: "A", IsFrozen: true, PendingAttempts: 1}),
+	}
+	final := []*model.Result{
+		newResolverResult(teams["t1"], solved("A", 10)),
+		newResolverResult(teams["t2"], solved("A", 20), solved("B", 250)),
+		newResolverResult(teams["t3"], &model.ProblemResult{ProblemID: "A", Attempts: 1}),
+	}
+	return NewResolver(contest, frozen, final)
+}
+
+func TestResolverSteps(t *testing.T) {
+	resolver := newTestResolver()
+
+	want := []ResolverStep{
+		{Index: 1, Type: ResolverStepReveal, TeamID: "t3", TeamName: "Team t3", ProblemID: "A", OldRank: 3, NewRank: 3},
+		{Index: 2, Type: ResolverStepReveal, TeamID: "t2", TeamName: "Team t2", ProblemID: "B", Solved: true, OldRank: 2, NewRank: 1},
+		{Index: 3, Type: ResolverStepAward, TeamID: "t1", TeamName: "Team t1", OldRank: 2, NewRank: 2, Medal: model.MedalSilver},
+		{Index: 4, Type: ResolverStepAward, TeamID: "t2", TeamName: "Team t2", OldRank: 1, NewRank: 1, Medal: model.MedalGold},
+	}
+	if !reflect.DeepEqual(resolver.Steps, want) {
+		t.Errorf("steps = %+v\nwant %+v", resolver.Steps, want)
+	}
+}
+
+func TestResolverBoardAt(t *testing.T) {
+	resolver := newTestResolver()
+
+	tests := []struct {
+		cursor    int
+		wantTeams []string
+		wantRanks []int
+		wantMedal map[string]string
+	}{
+		{0, []string{"t1", "t2", "t3"}, []int{1, 2, 3}, map[string]string{}},
+		{1, []string{"t1", "t2", "t3"}, []int{1, 2, 3}, map[string]string{}},
+		{2, []string{"t2", "t1", "t3"}, []int{1, 2, 3}, map[string]string{}},
+		{4, []string{"t2", "t1", "t3"}, []int{1, 2, 3}, map[string]string{"t1": model.MedalSilver, "t2": model.MedalGold}},
+	}
+
+	for _, tt := range tests {
+		board, err := resolver.BoardAt(tt.cursor)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		var teams []string
+		var ranks []int
+		medals := make(map[string]string)
+		for _, result := range board {
+			teams = append(teams, result.TeamID)
+			ranks = append(ranks, result.Rank)
+			if result.Medal != "" {
+				medals[result.TeamID] = result.Medal
+			}
+		}
+		if !reflect.DeepEqual(teams, tt.wantTeams) || !reflect.DeepEqual(ranks, tt.wantRanks) {
+			t.Errorf("BoardAt(%d) = %v with ranks %v, want %v with ranks %v", tt.cursor, teams, ranks, tt.wantTeams, tt.wantRanks)
+		}
+		if !reflect.DeepEqual(medals, tt.wantMedal) {
+			t.Errorf("BoardAt(%d) medals = %v, want %v", tt.cursor, medals, tt.wantMedal)
+		}
+	}
+
+	// 回退到之前的位置不受已经生成的记分板影响
+	board, err := resolver.BoardAt(0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if board[1].ProblemResults["B"].Solved {
+		t.Error("BoardAt(0) after BoardAt(4) reveals problem B of t2")
+	}
+
+	for _, cursor := range []int{-1, len(resolver.Steps) + 1} {
+		if _, err := resolver.BoardAt(cursor); err == nil {
+			t.Errorf("BoardAt(%d) returned no error", cursor)
+		}
+	}
+}
+
+func TestGetResolverReusesResolver(t *testing.T) {
+	contest := modeltest.NewContest()
+	contest.FrozenTime = 3600
+	runs := []*model.Run{
+		modeltest.RunAt("1", "t1", 0, 30, "ACCEPTED"),
+		modeltest.RunAt("2", "t2", 1, 250, "ACCEPTED"),
+	}
+	store := model.NewMemoryStore()
+	if err := store.PutContest(contest.ID, contest, modeltest.NewTeams("t1", "t2"), runs); err != nil {
+		t.Fatal(err)
+	}
+	svc := NewScoreboardService(store)
+
+	resolver, _, err := svc.GetResolver(contest.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if again, _, _ := svc.GetResolver(contest.ID); again != resolver {
+		t.Error("resolver was rebuilt although the contest did not change")
+	}
+
+	// 提交变化后重新生成
+	runs = append(runs, modeltest.RunAt("3", "t1", 1, 260, "ACCEPTED"))
+	if err := store.PutRuns(contest.ID, runs); err != nil {
+		t.Fatal(err)
+	}
+	changed, _, err := svc.GetResolver(contest.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if changed == resolver || len(changed.Steps) == len(resolver.Steps) {
+		t.Errorf("got %d steps after a new run, want a new resolver with more steps than %d", len(changed.Steps), len(resolver.Steps))
+	}
+}
@@ -10,7 +10,9 @@ import (
 
 // ScoreboardService 提供记分板相关的服务
 type ScoreboardService struct {
-	// 不再需要缓存
+	store  model.ContestStore
+	cache  *contestCache // 比赛数据和计分结果的缓存
+	events *EventBus     // 比赛数据变化事件
 }
 
 // ContestInfo 比赛基本信息
@@ -22,15 +24,33 @@ type ContestInfo struct {
 	Type      string    `json:"type"`
 }
 
-// NewScoreboardService 创建一个新的记分板服务
-func NewScoreboardService() *ScoreboardService {
-	return &ScoreboardService{}
+// NewScoreboardService 创建一个使用 store 中比赛数据的记分板服务
+func NewScoreboardService(store model.ContestStore) *ScoreboardService {
+	return &ScoreboardService{
+		store:  store,
+		cache:  newContestCache(store, defaultCacheSize),
+		events: NewEventBus(),
+	}
+}
+
+// Events 获取比赛数据变化的事件总线
+func (s *ScoreboardService) Events() *EventBus {
+	return s.events
+}
+
+// getSnapshot 获取比赛计分引擎的最新快照
+func (s *ScoreboardService) getSnapshot(contestID string) (*Snapshot, error) {
+	engine, err := s.cache.get(contestID)
+	if err != nil {
+		return nil, err
+	}
+	return engine.Snapshot(), nil
 }
 
 // GetAllContests 获取所有比赛信息
 func (s *ScoreboardService) GetAllContests() ([]ContestInfo, error) {
-	// 直接从目录文件读取
-	contests, err := model.LoadAllContests()
+	// 直接从比赛目录读取
+	contests, err := model.LoadAllContests(s.store)
 	if err != nil {
 		return nil, fmt.Errorf("failed to load contests: %w", err)
 	}
@@ -66,13 +86,31 @@ func (s *ScoreboardService) GetAllContests() ([]ContestInfo, error) {
 	return contestInfos, nil
 }
 
-// GetContest 获取指定比赛
+// GetContest 获取指定比赛，返回的比赛来自缓存，调用方不能修改
 func (s *ScoreboardService) GetContest(contestID string) (*model.Contest, error) {
-	contest, err := model.LoadContestConfig(contestID)
+	engine, err := s.cache.get(contestID)
+	if err != nil {
+		return nil, err
+	}
+
+	return engine.Contest(), nil
+}
+
+// SetUnfreezeTime 设置比赛解除封榜的时刻，传入 0 表示恢复封榜
+func (s *ScoreboardService) SetUnfreezeTime(contestID string, unfreezeTime int64) (*model.Contest, error) {
+	// 缓存中的比赛不能修改，重新加载配置
+	contest, err := model.LoadContestConfig(s.store, contestID)
 	if err != nil {
 		return nil, fmt.Errorf("contest not found: %s", err)
 	}
 
+	if err := contest.SetUnfreezeTime(unfreezeTime); err != nil {
+		return nil, fmt.Errorf("failed to update freeze state: %w", err)
+	}
+
+	// 封榜状态变化后需要重新计分
+	s.cache.invalidate(contestID)
+
 	return contest, nil
 }
 
@@ -86,32 +124,79 @@ func (s *ScoreboardService) GetTimeInfo(contest *model.Contest) map[string]inter
 	return contest.GetTimeInfo()
 }
 
+// Scoreboard 记分板数据
+type Scoreboard struct {
+	Contest     *model.Contest  `json:"contest"`
+	Version     uint64          `json:"version"` // 计分快照版本，可用于请求增量
+	Results     []*model.Result `json:"results"`
+	FirstSolves []FirstSolve    `json:"first_solves"` // 全场各题一血，不随筛选条件变化
+	Warnings    []model.Warning `json:"warnings"`     // 处理提交记录时发现的异常
+}
+
 // GetScoreboardWithFilter 统一处理所有筛选参数获取记分板数据
-func (s *ScoreboardService) GetScoreboardWithFilter(contestID string, filter string) ([]*model.Result, *model.Contest, error) {
-	// 获取比赛信息
-	contest, err := s.GetContest(contestID)
+// asOf 为截至时刻（Unix时间戳，秒），为 0 时使用当前时间
+func (s *ScoreboardService) GetScoreboardWithFilter(contestID string, filter string, asOf int64) (*Scoreboard, error) {
+	// 获取计分引擎的最新快照
+	snapshot, err := s.getSnapshot(contestID)
 	if err != nil {
-		return nil, nil, err
+		return nil, err
 	}
 
-	// 获取所有可见结果（原始数据，不包含排名）
-	results, err := contest.GetVisibleResults()
-	if err != nil {
-		return nil, nil, fmt.Errorf("failed to get results: %w", err)
+	return snapshotScoreboard(snapshot, filter, asOf)
+}
+
+// snapshotScoreboard 从计分快照生成筛选后的记分板
+func snapshotScoreboard(snapshot *Snapshot, filter string, asOf int64) (*Scoreboard, error) {
+	contest := snapshot.Contest
+
+	// 快照中的结果已经在全部队伍上计算了排名、全场一血和奖牌，这些结果不随筛选条件变化
+	// 快照与截至时刻的结果不同时，使用内存中的提交重新计算
+	var results []*model.Result
+	firstSolves := snapshot.FirstSolves
+	if asOf <= 0 || snapshotCovers(snapshot, asOf) {
+		results = cloneResults(snapshot.Results)
+	} else {
+		results = contest.ScoreRuns(snapshot.Teams, snapshot.Runs, asOf)
+		RecalculateRanking(results, contest)
+		firstSolves = MarkGlobalFirstSolves(results, contest)
+		AssignMedals(results, contest.MedalRanks)
 	}
 
-	// 进行筛选（如果需要）
+	// 进行筛选（如果需要），并重新计算排名和首A
 	if filter != "" && filter != "all" {
+		var err error
 		results, err = FilterResults(results, filter)
 		if err != nil {
-			return nil, nil, fmt.Errorf("failed to filter results: %w", err)
+			return nil, fmt.Errorf("failed to filter results: %w", err)
 		}
+		RecalculateRanking(results, contest)
 	}
 
-	// 计算排名和首A
-	RecalculateRanking(results)
+	return &Scoreboard{
+		Contest:     contest,
+		Version:     snapshot.Version,
+		Results:     results,
+		FirstSolves: firstSolves,
+		Warnings:    snapshot.Warnings,
+	}, nil
+}
+
+// snapshotCovers 判断截至 asOf 时刻的记分板是否与计分快照的结果相同
+// 比赛结束后所有提交都已计入，只需封榜状态相同
+func snapshotCovers(snapshot *Snapshot, asOf int64) bool {
+	contest := snapshot.Contest
+	return asOf >= contest.EndTime && contest.IsBoardFrozen(asOf, time.Now().Unix()) == snapshot.Frozen
+}
+
+// GetResolver 获取比赛颁奖仪式的揭晓过程，计分快照没有变化时复用已生成的揭晓过程
+func (s *ScoreboardService) GetResolver(contestID string) (*Resolver, *model.Contest, error) {
+	engine, err := s.cache.get(contestID)
+	if err != nil {
+		return nil, nil, err
+	}
+	snapshot := engine.Snapshot()
 
-	return results, contest, nil
+	return engine.Resolver(snapshot), snapshot.Contest, nil
 }
 
 // FilterResults 根据筛选条件过滤结果
@@ -121,14 +206,7 @@ func FilterResults(results []*model.Result, filter string) ([]*model.Result, err
 	for _, result := range results {
 		switch filter {
 		case "official": // 正式队伍
-			isOfficial := true
-			for _, group := range result.Team.Groups {
-				if group == "unofficial" {
-					isOfficial = false
-					break
-				}
-			}
-			if isOfficial {
+			if result.Team.IsOfficial() {
 				filteredResults = append(filteredResults, result)
 			}
 		case "unofficial": // 打星队伍
@@ -164,17 +242,16 @@ func FilterResults(results []*model.Result, filter string) ([]*model.Result, err
 	return filteredResults, nil
 }
 
-// RecalculateRanking 重新计算筛选后的排名、学校排名和首A
-func RecalculateRanking(results []*model.Result) {
+// RecalculateRanking 按比赛的计分规则重新计算筛选后的排名、学校排名和首A
+// 打星队伍按成绩显示在榜单中的对应位置（Rank），但不占用正式排名和学校排名，
+// 除非比赛配置允许，否则也不参与首A
+func RecalculateRanking(results []*model.Result, contest *model.Contest) {
+	rules := contest.RuleSet()
+
 	// 1. 排名计算
-	// 按解题数和罚时排序
-	sort.Slice(results, func(i, j int) bool {
-		// 首先按解题数量排序（降序）
-		if results[i].Score != results[j].Score {
-			return results[i].Score > results[j].Score
-		}
-		// 如果解题数量相同，按罚时排序（升序）
-		return results[i].TotalTime < results[j].TotalTime
+	// 按计分规则排序
+	sort.SliceStable(results, func(i, j int) bool {
+		return rules.Compare(results[i], results[j]) < 0
 	})
 
 	// 设置队伍排名，处理并列排名
@@ -182,29 +259,42 @@ func RecalculateRanking(results []*model.Result) {
 		result.Rank = i + 1
 		if i > 0 {
 			prev := results[i-1]
-			if prev.Score == result.Score && prev.TotalTime == result.TotalTime {
+			if rules.Compare(prev, result) == 0 {
 				result.Rank = prev.Rank
 			}
 		}
 	}
 
-	// 2. 学校排名计算
-	schoolRankMap := make(map[string]int)  // 学校名称 -> 排名
-	schoolsRanked := make(map[string]bool) // 已经排名的学校
-
+	// 设置正式排名，只在正式队伍之间计算
+	officialCount := 0
+	var prevOfficial *model.Result
 	for _, result := range results {
-		school := result.Team.Organization
-		if school == "" || schoolsRanked[school] {
-			continue // 跳过没有学校信息或已经处理过的学校
+		result.OfficialRank = 0
+		if !result.Team.IsOfficial() {
+			continue
+		}
+
+		officialCount++
+		result.OfficialRank = officialCount
+		if prevOfficial != nil && rules.Compare(prevOfficial, result) == 0 {
+			result.OfficialRank = prevOfficial.OfficialRank
 		}
+		prevOfficial = result
+	}
 
-		// 为新学校分配排名（按照队伍出现顺序，即排名顺序）
-		schoolRankMap[school] = len(schoolRankMap) + 1
-		schoolsRanked[school] = true
+	// 2. 学校排名计算
+	// 按学校最好的正式队伍排名，最好队伍并列的学校排名也并列
+	schoolRankMap := make(map[string]int) // 学校名称 -> 排名
+	for _, organization := range RankOrganizations(results, OrganizationOptions{ExcludeUnofficial: true}) {
+		schoolRankMap[organization.Organization] = organization.Rank
 	}
 
-	// 更新每个队伍的学校排名
+	// 更新每个正式队伍的学校排名
 	for _, result := range results {
+		result.SchoolRank = 0
+		if !result.Team.IsOfficial() {
+			continue
+		}
 		school := result.Team.Organization
 		if rank, exists := schoolRankMap[school]; exists {
 			result.SchoolRank = rank
@@ -212,46 +302,10 @@ func RecalculateRanking(results []*model.Result) {
 	}
 
 	// 3. 首A计算
-	// 记录每个题目的最早解出时间和对应队伍
-	firstSolveMap := make(map[string]struct {
-		TeamID    string
-		SolveTime int64
-	})
-
-	// 第一遍遍历，找出每道题目的最早解出时间
+	firstSolves := FindFirstSolves(results, contest)
 	for _, result := range results {
 		for problemID, problem := range result.ProblemResults {
-			if problem.Solved {
-				// 如果是首次记录该题，或解题时间早于之前记录
-				if firstSolve, exists := firstSolveMap[problemID]; !exists || problem.SolvedTime < firstSolve.SolveTime {
-					firstSolveMap[problemID] = struct {
-						TeamID    string
-						SolveTime int64
-					}{
-						TeamID:    result.Team.ID,
-						SolveTime: problem.SolvedTime,
-					}
-				}
-			}
-		}
-	}
-
-	// 第二遍遍历，标记首A
-	for _, result := range results {
-		for problemID, problem := range result.ProblemResults {
-			if problem.Solved {
-				// 重置首A标记
-				problem.FirstToSolve = false
-
-				// 检查是否是当前题目的首A
-				if firstSolve, exists := firstSolveMap[problemID]; exists &&
-					result.Team.ID == firstSolve.TeamID &&
-					problem.SolvedTime == firstSolve.SolveTime {
-					problem.FirstToSolve = true
-				}
-
-				result.ProblemResults[problemID] = problem
-			}
+			problem.FirstToSolve = isFirstSolve(firstSolves, problemID, result.TeamID)
 		}
 	}
 }
@@ -279,22 +333,21 @@ type ProblemStatistic struct {
 
 // GetContestStatistics 获取比赛的统计信息
 func (s *ScoreboardService) GetContestStatistics(contestID string, filter string) (*ContestStatistics, error) {
-	// 获取比赛信息
-	contest, err := s.GetContest(contestID)
+	// 获取计分引擎的最新快照，包括比赛信息、结果和提交记录
+	snapshot, err := s.getSnapshot(contestID)
 	if err != nil {
 		return nil, err
 	}
+	contest := snapshot.Contest
+	runs := snapshot.Runs
 
 	// 获取所有结果（根据筛选条件）
-	results, _, err := s.GetScoreboardWithFilter(contestID, filter)
-	if err != nil {
-		return nil, fmt.Errorf("failed to get results: %w", err)
-	}
-
-	// 获取原始提交记录
-	runs, err := contest.LoadRuns()
-	if err != nil {
-		return nil, fmt.Errorf("failed to load runs: %w", err)
+	results := snapshot.Results
+	if filter != "" && filter != "all" {
+		results, err = FilterResults(results, filter)
+		if err != nil {
+			return nil, fmt.Errorf("failed to filter results: %w", err)
+		}
 	}
 
 	// 创建筛选的队伍ID集合
@@ -339,7 +392,7 @@ func (s *ScoreboardService) GetContestStatistics(contestID string, filter string
 
 	// 统计队伍解题数
 	for _, result := range results {
-		stats.TeamSolvedCount[result.Score]++
+		stats.TeamSolvedCount[result.SolvedCount()]++
 	}
 
 	// 处理提交记录，填充热力图数据
@@ -349,8 +402,10 @@ func (s *ScoreboardService) GetContestStatistics(contestID string, filter string
 			continue
 		}
 
+		// 封榜后的提交按封榜状态统计，不公开评测结果
+		verdict := visibleVerdict(snapshot, run)
 		stats.SubmissionCount++
-		stats.SubmissionTypes[run.Status]++
+		stats.SubmissionTypes[string(verdict)]++
 
 		// 确保题目ID在有效范围内
 		if run.ProblemID >= 0 && run.ProblemID < len(contest.ProblemIDs) {
@@ -359,14 +414,13 @@ func (s *ScoreboardService) GetContestStatistics(contestID string, filter string
 			problemStat := stats.ProblemStats[problemID]
 
 			// 计算该提交所在的时间段
-			relativeMinutes := run.Timestamp / 1000 / 60 // 转换为分钟
-			position := int(relativeMinutes / 5)         // 每5分钟一个时间段
+			position := int(run.Minute() / 5) // 每5分钟一个时间段
 
 			// 确保时间段索引在有效范围内
 			if position >= 0 && position < timeSlotCount {
 				// 更新题目热力图数据
-				switch run.Status {
-				case "ACCEPTED":
+				switch {
+				case verdict.IsAccepted():
 					stats.ProblemHeatmap[problemID]["accepted"][position]++
 				default:
 					stats.ProblemHeatmap[problemID]["rejected"][position]++
@@ -374,10 +428,10 @@ func (s *ScoreboardService) GetContestStatistics(contestID string, filter string
 			}
 
 			// 更新题目统计信息
-			switch run.Status {
-			case "ACCEPTED":
+			switch {
+			case verdict.IsAccepted():
 				problemStat.Accepted++
-			case "frozen":
+			case verdict.IsPending():
 				problemStat.Pending++
 			default:
 				problemStat.Rejected++
@@ -402,41 +456,69 @@ func (s *ScoreboardService) GetContestStatistics(contestID string, filter string
 // SubmissionRecord 定义提交记录的返回格式
 type SubmissionRecord struct {
 	ID         string `json:"id"`
-	Status     string `json:"status"`
+	Status     string `json:"status"`  // 数据中的原始评测结果
+	Verdict    string `json:"verdict"` // 统一后的评测结果
 	TeamID     string `json:"team_id"`
 	TeamName   string `json:"team_name"`
 	School     string `json:"school"`
 	ProblemID  string `json:"problem_id"`
-	Timestamp  int64  `json:"timestamp"`
+	Timestamp  int64  `json:"timestamp"` // 相对比赛开始的毫秒数
 	Language   string `json:"language"`
 	IsFiltered bool   `json:"is_filtered,omitempty"`
 }
 
-// GetSubmissions 获取比赛的提交记录
-func (s *ScoreboardService) GetSubmissions(contestID string, filter string, page int, pageSize int) ([]*SubmissionRecord, int, error) {
-	// 获取比赛信息
-	contest, err := s.GetContest(contestID)
-	if err != nil {
-		return nil, 0, err
+// newSubmissionRecord 把提交转换为返回格式，题目ID无效时返回 nil
+// 快照封榜时封榜后的提交不公开评测结果，原始评测结果和统一后的评测结果都显示为 FROZEN
+func newSubmissionRecord(snapshot *Snapshot, run *model.Run) *SubmissionRecord {
+	contest := snapshot.Contest
+	// 确保题目ID在有效范围内
+	if run.ProblemID < 0 || run.ProblemID >= len(contest.ProblemIDs) {
+		return nil
 	}
 
-	// 加载原始提交记录
-	runs, err := contest.LoadRuns()
-	if err != nil {
-		return nil, 0, fmt.Errorf("failed to load runs: %w", err)
+	// 获取队伍信息 - 添加检查以避免空指针
+	team, exists := snapshot.Teams[run.TeamID]
+	if !exists {
+		// 如果找不到队伍信息，使用默认值
+		team = &model.Team{
+			ID:           run.TeamID,
+			Name:         "未知队伍",
+			Organization: "未知学校",
+		}
+	}
+
+	status, verdict := run.Status, visibleVerdict(snapshot, run)
+	if verdict != run.Verdict {
+		status = string(verdict)
+	}
+
+	return &SubmissionRecord{
+		ID:        run.ID,
+		Status:    status,
+		Verdict:   string(verdict),
+		TeamID:    run.TeamID,
+		TeamName:  team.Name,
+		School:    team.Organization,
+		ProblemID: contest.ProblemIDs[run.ProblemID],
+		Timestamp: run.Time.Milliseconds(),
+		Language:  run.Language,
 	}
+}
 
-	// 加载队伍信息
-	teams, err := contest.LoadTeams()
+// GetSubmissions 获取比赛的提交记录
+func (s *ScoreboardService) GetSubmissions(contestID string, filter string, page int, pageSize int) ([]*SubmissionRecord, int, error) {
+	// 获取计分引擎的最新快照，包括比赛信息、队伍和提交记录
+	snapshot, err := s.getSnapshot(contestID)
 	if err != nil {
-		return nil, 0, fmt.Errorf("failed to load teams: %w", err)
+		return nil, 0, err
 	}
+	runs := snapshot.Runs
 
 	// 处理筛选
 	var filteredTeamIDs map[string]bool
 	if filter != "" && filter != "all" {
 		// 获取符合筛选条件的队伍列表
-		results, _, err := s.GetScoreboardWithFilter(contestID, filter)
+		results, err := FilterResults(snapshot.Results, filter)
 		if err != nil {
 			return nil, 0, fmt.Errorf("failed to filter teams: %w", err)
 		}
@@ -447,45 +529,30 @@ func (s *ScoreboardService) GetSubmissions(contestID string, filter string, page
 		}
 	}
 
+	// 如果未指定分页参数，使用默认值
+	if page <= 0 {
+		page = 1
+	}
+	if pageSize <= 0 {
+		pageSize = 15 // 默认每页15条记录
+	}
+
+	// 存储支持查询时直接在存储中筛选和分页
+	if querier, ok := s.store.(model.RunQuerier); ok {
+		return s.querySubmissions(querier, snapshot, filteredTeamIDs, page, pageSize)
+	}
+
 	// 转换为查询结果格式
 	var submissionRecords []*SubmissionRecord
 	for _, run := range runs {
-		// 确保题目ID在有效范围内
-		if run.ProblemID < 0 || run.ProblemID >= len(contest.ProblemIDs) {
+		record := newSubmissionRecord(snapshot, run)
+		if record == nil {
 			continue
 		}
 
-		// 从数字索引获取字母ID
-		problemID := contest.ProblemIDs[run.ProblemID]
-
-		// 获取队伍信息 - 添加检查以避免空指针
-		team, exists := teams[run.TeamID]
-		if !exists {
-			// 如果找不到队伍信息，使用默认值
-			team = &model.Team{
-				ID:           run.TeamID,
-				Name:         "未知队伍",
-				Organization: "未知学校",
-			}
-		}
-
 		// 检查是否被筛选
-		isFiltered := false
 		if filteredTeamIDs != nil && !filteredTeamIDs[run.TeamID] {
-			isFiltered = true
-		}
-
-		// 创建提交记录
-		record := &SubmissionRecord{
-			ID:         run.ID,
-			Status:     run.Status,
-			TeamID:     run.TeamID,
-			TeamName:   team.Name,
-			School:     team.Organization,
-			ProblemID:  problemID,
-			Timestamp:  run.Timestamp,
-			Language:   run.Language,
-			IsFiltered: isFiltered,
+			record.IsFiltered = true
 		}
 
 		submissionRecords = append(submissionRecords, record)
@@ -505,14 +572,6 @@ func (s *ScoreboardService) GetSubmissions(contestID string, filter string, page
 		}
 	}
 
-	// 如果未指定分页参数，使用默认值
-	if page <= 0 {
-		page = 1
-	}
-	if pageSize <= 0 {
-		pageSize = 15 // 默认每页15条记录
-	}
-
 	// 应用分页（只返回符合筛选条件的记录）
 	var pagedRecords []*SubmissionRecord
 	count := 0
@@ -537,3 +596,47 @@ func (s *ScoreboardService) GetSubmissions(contestID string, filter string, page
 
 	return pagedRecords, totalCount, nil
 }
+
+// querySubmissions 在存储中筛选和分页查询提交记录，filteredTeamIDs 为 nil 时不筛选
+func (s *ScoreboardService) querySubmissions(querier model.RunQuerier, snapshot *Snapshot,
+	filteredTeamIDs map[string]bool, page int, pageSize int) ([]*SubmissionRecord, int, error) {
+	contest := snapshot.Contest
+	query := model.RunQuery{
+		ProblemCount: len(contest.ProblemIDs),
+		Offset:       (page - 1) * pageSize,
+		Limit:        pageSize,
+	}
+	if filteredTeamIDs != nil {
+		query.TeamIDs = make([]string, 0, len(filteredTeamIDs))
+		for teamID := range filteredTeamIDs {
+			query.TeamIDs = append(query.TeamIDs, teamID)
+		}
+	}
+
+	runs, totalCount, err := querier.QueryRuns(contest.ID, query)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to query submissions: %w", err)
+	}
+
+	// 统一时间戳单位
+	contest.NormalizeRuns(runs)
+
+	var pagedRecords []*SubmissionRecord
+	for _, run := range runs {
+		if record := newSubmissionRecord(snapshot, run); record != nil {
+			pagedRecords = append(pagedRecords, record)
+		}
+	}
+
+	return pagedRecords, totalCount, nil
+}
+
+// visibleVerdict 获取提交在快照中对外公开的评测结果，快照封榜时封榜后的提交显示为 FROZEN
+func visibleVerdict(snapshot *Snapshot, run *model.Run) model.Verdict {
+	contest := snapshot.Contest
+	frozenStart := contest.Duration() - time.Duration(contest.FrozenTime)*time.Second
+	if snapshot.Frozen && contest.FrozenTime > 0 && run.Time >= frozenStart {
+		return model.VerdictFrozen
+	}
+	return run.Verdict
+}
@@ -0,0 +1,140 @@
+package service
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/lllllan02/scoreboard/internal/model"
+	"github.com/lllllan02/scoreboard/internal/model/modeltest"
+)
+
+func TestVisibleVerdict(t *testing.T) {
+	contest := modeltest.NewContest()
+	contest.FrozenTime = 3600
+	// 比赛时长 5 小时，最后一小时封榜
+	before := modeltest.RunAt("1", "t1", 0, 239, "ACCEPTED")
+	after := modeltest.RunAt("2", "t1", 0, 240, "WRONG_ANSWER")
+	contest.NormalizeRuns([]*model.Run{before, after})
+
+	tests := []struct {
+		name       string
+		frozenTime int64
+		frozen     bool
+		run        *model.Run
+		want       model.Verdict
+	}{
+		{"before the freeze", 3600, true, before, model.VerdictAccepted},
+		{"after the freeze", 3600, true, after, model.VerdictFrozen},
+		{"unfrozen", 3600, false, after, model.VerdictWrongAnswer},
+		{"no freeze", 0, true, after, model.VerdictWrongAnswer},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			contest.FrozenTime = tt.frozenTime
+			snapshot := &Snapshot{Contest: contest, Frozen: tt.frozen}
+			if got := visibleVerdict(snapshot, tt.run); got != tt.want {
+				t.Errorf("visibleVerdict() = %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
+
+// newFrozenTestService 创建最后一小时封榜且尚未解除封榜的比赛
+func newFrozenTestService(t *testing.T, ruleSet string) (*ScoreboardService, string) {
+	t.Helper()
+
+	contest := modeltest.NewContest()
+	contest.FrozenTime = 3600
+	contest.Options.RuleSet = ruleSet
+	runs := []*model.Run{
+		modeltest.RunAt("1", "t1", 0, 30, "wa"),
+		modeltest.RunAt("2", "t1", 0, 60, "AC"),
+		modeltest.RunAt("3", "t2", 1, 90, "ACCEPTED"),
+		modeltest.RunAt("4", "t2", 0, 250, "ACCEPTED"),
+		modeltest.RunAt("5", "t1", 1, 280, "WRONG_ANSWER"),
+	}
+
+	store := model.NewMemoryStore()
+	if err := store.PutContest(contest.ID, contest, modeltest.NewTeams("t1", "t2", "t3"), runs); err != nil {
+		t.Fatal(err)
+	}
+	return NewScoreboardService(store), contest.ID
+}
+
+func TestGetSubmissionsHidesFrozenVerdicts(t *testing.T) {
+	svc, contestID := newFrozenTestService(t, "icpc")
+
+	records, total, err := svc.GetSubmissions(contestID, "", 1, 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if total != 5 {
+		t.Fatalf("total = %d, want 5", total)
+	}
+
+	type submission struct{ ID, Status, Verdict string }
+	var got []submission
+	for _, record := range records {
+		got = append(got, submission{record.ID, record.Status, record.Verdict})
+	}
+	want := []submission{
+		{"5", "FROZEN", "FROZEN"},
+		{"4", "FROZEN", "FROZEN"},
+		{"3", "ACCEPTED", "ACCEPTED"},
+		{"2", "AC", "ACCEPTED"},
+		{"1", "wa", "WRONG_ANSWER"},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("submissions = %v, want %v", got, want)
+	}
+}
+
+func TestGetContestStatistics(t *testing.T) {
+	tests := []struct {
+		ruleSet         string
+		wantTypes       map[string]int
+		wantSolvedCount map[int]int
+	}{
+		{
+			ruleSet:         "icpc",
+			wantTypes:       map[string]int{"WRONG_ANSWER": 1, "ACCEPTED": 2, "FROZEN": 2},
+			wantSolvedCount: map[int]int{0: 1, 1: 2, 2: 0, 3: 0},
+		},
+		{
+			// 按得分排名时解题数不等于得分
+			ruleSet:         "codeforces",
+			wantTypes:       map[string]int{"WRONG_ANSWER": 1, "ACCEPTED": 2, "FROZEN": 2},
+			wantSolvedCount: map[int]int{0: 1, 1: 2, 2: 0, 3: 0},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.ruleSet, func(t *testing.T) {
+			svc, contestID := newFrozenTestService(t, tt.ruleSet)
+
+			stats, err := svc.GetContestStatistics(contestID, "")
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(stats.SubmissionTypes, tt.wantTypes) {
+				t.Errorf("SubmissionTypes = %v, want %v", stats.SubmissionTypes, tt.wantTypes)
+			}
+			if !reflect.DeepEqual(stats.TeamSolvedCount, tt.wantSolvedCount) {
+				t.Errorf("TeamSolvedCount = %v, want %v", stats.TeamSolvedCount, tt.wantSolvedCount)
+			}
+
+			// 封榜后的通过不计入热力图的通过数
+			accepted := 0
+			for _, count := range stats.ProblemHeatmap["A"]["accepted"] {
+				accepted += count
+			}
+			if accepted != 1 {
+				t.Errorf("accepted runs on A in the heatmap = %d, want 1", accepted)
+			}
+			if stats.ProblemStats["A"].Pending != 1 {
+				t.Errorf("pending runs on A = %d, want 1", stats.ProblemStats["A"].Pending)
+			}
+		})
+	}
+}
@@ -0,0 +1,112 @@
+package service
+
+import (
+	"context"
+	"log"
+	"time"
+
+	"github.com/lllllan02/scoreboard/internal/model"
+)
+
+// DefaultWatchInterval 默认检查数据文件变化的间隔
+const DefaultWatchInterval = 2 * time.Second
+
+// Watcher 定期检查存储中比赛数据的版本标记，发现变化时只重新加载受影响的比赛，
+// 并在事件总线上发布比赛更新事件
+type Watcher struct {
+	svc      *ScoreboardService
+	interval time.Duration
+	stamps   map[string]model.ContestStamp // 比赛ID -> 上次检查时的版本标记
+	versions map[string]uint64             // 比赛ID -> 上次发布事件时的快照版本
+}
+
+// NewWatcher 创建比赛数据监听器，interval 为检查间隔
+func NewWatcher(svc *ScoreboardService, interval time.Duration) *Watcher {
+	if interval <= 0 {
+		interval = DefaultWatchInterval
+	}
+	return &Watcher{
+		svc:      svc,
+		interval: interval,
+		versions: make(map[string]uint64),
+	}
+}
+
+// Run 持续监听比赛数据，直到 ctx 被取消
+func (w *Watcher) Run(ctx context.Context) {
+	ticker := time.NewTicker(w.interval)
+	defer ticker.Stop()
+
+	w.scan()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			w.scan()
+		}
+	}
+}
+
+// scan 检查一遍所有比赛的数据，第一次检查只记录版本标记
+func (w *Watcher) scan() {
+	contestIDs, err := w.svc.store.ListContests()
+	if err != nil {
+		log.Printf("扫描比赛数据失败: %v", err)
+		return
+	}
+
+	first := w.stamps == nil
+	stamps := make(map[string]model.ContestStamp, len(contestIDs))
+	for _, contestID := range contestIDs {
+		current, err := w.svc.store.Stamp(contestID)
+		if err != nil {
+			// 文件正在被写入或删除，下次再检查
+			if old, ok := w.stamps[contestID]; ok {
+				stamps[contestID] = old
+			}
+			continue
+		}
+		stamps[contestID] = current
+
+		// 数据变化、到达安排的解除封榜时刻，或者已被请求重新加载时发布更新
+		old, ok := w.stamps[contestID]
+		if (first || (ok && old == current)) && !w.svc.cache.changedSince(contestID, w.versions[contestID]) {
+			continue
+		}
+		w.reload(contestID)
+	}
+
+	for contestID := range w.stamps {
+		if _, ok := stamps[contestID]; !ok {
+			w.svc.cache.remove(contestID)
+			delete(w.versions, contestID)
+			w.svc.events.Publish(Event{Type: EventContestRemoved, ContestID: contestID, Time: time.Now()})
+		}
+	}
+
+	w.stamps = stamps
+}
+
+// reload 重新加载比赛，计分快照发生变化时发布更新事件
+func (w *Watcher) reload(contestID string) {
+	snapshot, err := w.svc.getSnapshot(contestID)
+	if err != nil {
+		log.Printf("重新加载比赛 %s 失败: %v", contestID, err)
+		return
+	}
+
+	// 文件被改写但内容没有变化
+	if w.versions[contestID] == snapshot.Version {
+		return
+	}
+	w.versions[contestID] = snapshot.Version
+
+	log.Printf("比赛 %s 数据已更新，版本 %d", contestID, snapshot.Version)
+	w.svc.events.Publish(Event{
+		Type:      EventContestUpdated,
+		ContestID: contestID,
+		Version:   snapshot.Version,
+		Time:      time.Now(),
+	})
+}
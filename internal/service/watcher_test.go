@@ -0,0 +1,65 @@
+package service
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/lllllan02/scoreboard/internal/model"
+	"github.com/lllllan02/scoreboard/internal/model/modeltest"
+)
+
+// nextEvent 获取已经发布的下一个事件，没有事件时返回 false
+func nextEvent(ch <-chan Event) (Event, bool) {
+	select {
+	case event := <-ch:
+		return event, true
+	default:
+		return Event{}, false
+	}
+}
+
+func TestWatcherPublishesEvents(t *testing.T) {
+	root := t.TempDir()
+	store := model.NewFileStore(root)
+	contest := modeltest.NewContest()
+	teams := modeltest.NewTeams("t1", "t2")
+	runs := []*model.Run{modeltest.RunAt("1", "t1", 0, 10, "ACCEPTED")}
+	modeltest.ImportContest(t, store, contest, teams, runs)
+
+	svc := NewScoreboardService(store)
+	events, cancel := svc.Events().Subscribe(contest.ID)
+	defer cancel()
+	watcher := NewWatcher(svc, time.Hour)
+
+	// 第一次检查只记录版本标记
+	watcher.scan()
+	if event, ok := nextEvent(events); ok {
+		t.Fatalf("got %+v on the first scan, want no event", event)
+	}
+
+	// 提交记录变化
+	runs = append(runs, modeltest.RunAt("2", "t2", 1, 20, "ACCEPTED"))
+	modeltest.ImportContest(t, store, contest, teams, runs)
+	watcher.scan()
+	event, ok := nextEvent(events)
+	if !ok || event.Type != EventContestUpdated || event.ContestID != contest.ID || event.Version == 0 {
+		t.Fatalf("got %+v after runs changed, want a contest_updated event", event)
+	}
+
+	// 数据没有变化
+	watcher.scan()
+	if event, ok := nextEvent(events); ok {
+		t.Fatalf("got %+v without changes, want no event", event)
+	}
+
+	// 比赛被删除
+	if err := os.RemoveAll(filepath.Join(root, contest.ID)); err != nil {
+		t.Fatal(err)
+	}
+	watcher.scan()
+	if event, ok := nextEvent(events); !ok || event.Type != EventContestRemoved || event.ContestID != contest.ID {
+		t.Fatalf("got %+v after the contest was removed, want a contest_removed event", event)
+	}
+}
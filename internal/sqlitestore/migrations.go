@@ -0,0 +1,98 @@
+package sqlitestore
+
+import (
+	"database/sql"
+	"fmt"
+)
+
+// migrations 数据库结构的迁移脚本，按顺序执行，已执行的数量记录在 PRAGMA user_version 中
+// 只能在末尾追加新的迁移，不能修改已发布的迁移
+var migrations = []string{
+	// 1: 比赛、队伍、提交记录和比赛目录
+	`
+	CREATE TABLE contests (
+		id            TEXT PRIMARY KEY,
+		config        TEXT NOT NULL,    -- 原始 config.json
+		name          TEXT NOT NULL DEFAULT '',
+		start_time    INTEGER NOT NULL DEFAULT 0,
+		end_time      INTEGER NOT NULL DEFAULT 0,
+		organization  TEXT NOT NULL DEFAULT '',
+		unfreeze_time INTEGER,          -- 解除封榜的时刻，NULL 表示从未保存封榜状态
+		config_hash   TEXT NOT NULL DEFAULT '',
+		teams_hash    TEXT NOT NULL DEFAULT '',
+		runs_hash     TEXT NOT NULL DEFAULT '',
+		config_rev    INTEGER NOT NULL DEFAULT 0,
+		teams_rev     INTEGER NOT NULL DEFAULT 0,
+		runs_rev      INTEGER NOT NULL DEFAULT 0
+	);
+
+	CREATE TABLE teams (
+		contest_id   TEXT NOT NULL REFERENCES contests(id) ON DELETE CASCADE,
+		team_id      TEXT NOT NULL,
+		name         TEXT NOT NULL DEFAULT '',
+		organization TEXT NOT NULL DEFAULT '',
+		data         TEXT NOT NULL,     -- 原始队伍信息
+		PRIMARY KEY (contest_id, team_id)
+	);
+
+	CREATE TABLE runs (
+		contest_id    TEXT NOT NULL REFERENCES contests(id) ON DELETE CASCADE,
+		seq           INTEGER NOT NULL, -- 在 run.json 中的位置
+		submission_id TEXT NOT NULL,
+		team_id       TEXT NOT NULL,
+		problem_id    INTEGER NOT NULL,
+		timestamp     INTEGER NOT NULL,
+		status        TEXT NOT NULL,
+		language      TEXT NOT NULL DEFAULT '',
+		score         INTEGER NOT NULL DEFAULT 0,
+		PRIMARY KEY (contest_id, seq)
+	);
+	CREATE INDEX runs_by_time ON runs (contest_id, timestamp);
+	CREATE INDEX runs_by_submission ON runs (contest_id, submission_id, seq);
+
+	CREATE TABLE directory (
+		contest_id   TEXT PRIMARY KEY,
+		name         TEXT NOT NULL DEFAULT '',
+		start_time   INTEGER NOT NULL DEFAULT 0,
+		end_time     INTEGER NOT NULL DEFAULT 0,
+		organization TEXT NOT NULL DEFAULT '',
+		type         TEXT NOT NULL DEFAULT ''
+	);
+
+	CREATE TABLE metadata (
+		key   TEXT PRIMARY KEY,
+		value TEXT NOT NULL
+	);
+	`,
+}
+
+// migrate 执行尚未执行的迁移
+func migrate(db *sql.DB) error {
+	var version int
+	if err := db.QueryRow("PRAGMA user_version").Scan(&version); err != nil {
+		return fmt.Errorf("failed to read schema version: %w", err)
+	}
+	if version > len(migrations) {
+		return fmt.Errorf("database schema version %d is newer than supported version %d", version, len(migrations))
+	}
+
+	for i := version; i < len(migrations); i++ {
+		tx, err := db.Begin()
+		if err != nil {
+			return fmt.Errorf("failed to begin migration %d: %w", i+1, err)
+		}
+		if _, err := tx.Exec(migrations[i]); err != nil {
+			tx.Rollback()
+			return fmt.Errorf("failed to apply migration %d: %w", i+1, err)
+		}
+		if _, err := tx.Exec(fmt.Sprintf("PRAGMA user_version = %d", i+1)); err != nil {
+			tx.Rollback()
+			return fmt.Errorf("failed to update schema version: %w", err)
+		}
+		if err := tx.Commit(); err != nil {
+			return fmt.Errorf("failed to commit migration %d: %w", i+1, err)
+		}
+	}
+
+	return nil
+}
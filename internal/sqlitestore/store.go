@@ -0,0 +1,515 @@
+// Package sqlitestore 提供基于 SQLite 的比赛存储，使用纯 Go 实现的驱动，不依赖 cgo
+package sqlitestore
+
+import (
+	"crypto/sha256"
+	"database/sql"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+
+	_ "modernc.org/sqlite"
+
+	"github.com/lllllan02/scoreboard/internal/model"
+)
+
+// directorySavedKey metadata 表中记录比赛目录是否保存过的键
+const directorySavedKey = "directory_saved"
+
+// Store 基于 SQLite 的比赛存储
+type Store struct {
+	db *sql.DB
+}
+
+var (
+	_ model.ContestStore = (*Store)(nil)
+	_ model.RunQuerier   = (*Store)(nil)
+)
+
+// Open 打开 path 处的数据库，不存在时创建，并执行尚未执行的迁移
+// path 为 ":memory:" 时使用内存数据库
+func Open(path string) (*Store, error) {
+	dsn := "file:" + path + "?_pragma=busy_timeout(5000)&_pragma=journal_mode(WAL)&_pragma=foreign_keys(1)"
+	db, err := sql.Open("sqlite", dsn)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open database: %w", err)
+	}
+	if path == ":memory:" {
+		// 每个连接都是独立的内存数据库
+		db.SetMaxOpenConns(1)
+	}
+
+	if err := migrate(db); err != nil {
+		db.Close()
+		return nil, err
+	}
+
+	return &Store{db: db}, nil
+}
+
+// Close 关闭数据库
+func (s *Store) Close() error {
+	return s.db.Close()
+}
+
+// ListContests 返回所有比赛ID，按ID排序
+func (s *Store) ListContests() ([]string, error) {
+	rows, err := s.db.Query("SELECT id FROM contests ORDER BY id")
+	if err != nil {
+		return nil, fmt.Errorf("failed to list contests: %w", err)
+	}
+	defer rows.Close()
+
+	var contestIDs []string
+	for rows.Next() {
+		var contestID string
+		if err := rows.Scan(&contestID); err != nil {
+			return nil, fmt.Errorf("failed to scan contest: %w", err)
+		}
+		contestIDs = append(contestIDs, contestID)
+	}
+	return contestIDs, rows.Err()
+}
+
+// Stamp 用各部分数据的修订号作为版本标记
+func (s *Store) Stamp(contestID string) (model.ContestStamp, error) {
+	var configRev, teamsRev, runsRev int64
+	err := s.db.QueryRow("SELECT config_rev, teams_rev, runs_rev FROM contests WHERE id = ?", contestID).
+		Scan(&configRev, &teamsRev, &runsRev)
+	if errors.Is(err, sql.ErrNoRows) {
+		return model.ContestStamp{}, fmt.Errorf("contest %s %w", contestID, model.ErrNotExist)
+	}
+	if err != nil {
+		return model.ContestStamp{}, fmt.Errorf("failed to query contest: %w", err)
+	}
+
+	return model.ContestStamp{
+		Config: strconv.FormatInt(configRev, 10),
+		Teams:  strconv.FormatInt(teamsRev, 10),
+		Runs:   strconv.FormatInt(runsRev, 10),
+	}, nil
+}
+
+// LoadConfig 读取比赛配置
+func (s *Store) LoadConfig(contestID string) (*model.Contest, error) {
+	var config string
+	err := s.db.QueryRow("SELECT config FROM contests WHERE id = ?", contestID).Scan(&config)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, fmt.Errorf("contest %s %w", contestID, model.ErrNotExist)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to query contest: %w", err)
+	}
+
+	var contest model.Contest
+	if err := json.Unmarshal([]byte(config), &contest); err != nil {
+		return nil, fmt.Errorf("failed to parse config: %w", err)
+	}
+	return &contest, nil
+}
+
+// LoadTeams 读取比赛的队伍
+func (s *Store) LoadTeams(contestID string) (map[string]*model.Team, error) {
+	if err := s.checkContest(contestID); err != nil {
+		return nil, err
+	}
+
+	rows, err := s.db.Query("SELECT team_id, data FROM teams WHERE contest_id = ?", contestID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query teams: %w", err)
+	}
+	defer rows.Close()
+
+	teams := make(map[string]*model.Team)
+	for rows.Next() {
+		var teamID, data string
+		if err := rows.Scan(&teamID, &data); err != nil {
+			return nil, fmt.Errorf("failed to scan team: %w", err)
+		}
+
+		var team model.Team
+		if err := json.Unmarshal([]byte(data), &team); err != nil {
+			return nil, fmt.Errorf("failed to parse team %s: %w", teamID, err)
+		}
+		teams[teamID] = &team
+	}
+	return teams, rows.Err()
+}
+
+// LoadRuns 读取比赛的提交记录，按导入时的顺序排列
+func (s *Store) LoadRuns(contestID string) ([]*model.Run, error) {
+	if err := s.checkContest(contestID); err != nil {
+		return nil, err
+	}
+
+	rows, err := s.db.Query(`SELECT submission_id, status, team_id, problem_id, timestamp, language, score
+		FROM runs WHERE contest_id = ? ORDER BY seq`, contestID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query runs: %w", err)
+	}
+	defer rows.Close()
+
+	return scanRuns(rows)
+}
+
+// QueryRuns 在数据库中筛选和分页提交记录
+func (s *Store) QueryRuns(contestID string, query model.RunQuery) ([]*model.Run, int, error) {
+	if err := s.checkContest(contestID); err != nil {
+		return nil, 0, err
+	}
+
+	// 提交编号重复时只保留最后一条，通过 runs_by_submission 索引查找同编号的后续记录
+	where := `r.contest_id = ? AND r.problem_id >= 0 AND r.problem_id < ?
+		AND (r.submission_id = '' OR NOT EXISTS (SELECT 1 FROM runs l
+			WHERE l.contest_id = r.contest_id AND l.submission_id = r.submission_id AND l.seq > r.seq))`
+	args := []interface{}{contestID, query.ProblemCount}
+	if query.TeamIDs != nil {
+		if len(query.TeamIDs) == 0 {
+			return nil, 0, nil
+		}
+		where += " AND r.team_id IN (?" + strings.Repeat(", ?", len(query.TeamIDs)-1) + ")"
+		for _, teamID := range query.TeamIDs {
+			args = append(args, teamID)
+		}
+	}
+
+	var total int
+	if err := s.db.QueryRow("SELECT COUNT(*) FROM runs r WHERE "+where, args...).Scan(&total); err != nil {
+		return nil, 0, fmt.Errorf("failed to count runs: %w", err)
+	}
+
+	limit := query.Limit
+	if limit <= 0 {
+		limit = -1
+	}
+	rows, err := s.db.Query(`SELECT r.submission_id, r.status, r.team_id, r.problem_id, r.timestamp, r.language, r.score
+		FROM runs r WHERE `+where+` ORDER BY r.timestamp DESC, r.seq DESC LIMIT ? OFFSET ?`,
+		append(args, limit, query.Offset)...)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to query runs: %w", err)
+	}
+	defer rows.Close()
+
+	runs, err := scanRuns(rows)
+	if err != nil {
+		return nil, 0, err
+	}
+	return runs, total, nil
+}
+
+// LoadRaw 读取比赛的原始配置，team.json 和 run.json 由数据库中的队伍和提交记录生成
+func (s *Store) LoadRaw(contestID string) (config, teams, runs []byte, err error) {
+	var rawConfig string
+	err = s.db.QueryRow("SELECT config FROM contests WHERE id = ?", contestID).Scan(&rawConfig)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil, nil, fmt.Errorf("contest %s %w", contestID, model.ErrNotExist)
+	}
+	if err != nil {
+		return nil, nil, nil, fmt.Errorf("failed to query contest: %w", err)
+	}
+
+	if teams, err = s.rawTeams(contestID); err != nil {
+		return nil, nil, nil, err
+	}
+	runList, err := s.LoadRuns(contestID)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	if runs, err = json.Marshal(runList); err != nil {
+		return nil, nil, nil, fmt.Errorf("failed to marshal runs: %w", err)
+	}
+	return []byte(rawConfig), teams, runs, nil
+}
+
+// rawTeams 由每个队伍的原始信息生成 team.json
+func (s *Store) rawTeams(contestID string) ([]byte, error) {
+	rows, err := s.db.Query("SELECT team_id, data FROM teams WHERE contest_id = ?", contestID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query teams: %w", err)
+	}
+	defer rows.Close()
+
+	teams := make(map[string]json.RawMessage)
+	for rows.Next() {
+		var teamID, data string
+		if err := rows.Scan(&teamID, &data); err != nil {
+			return nil, fmt.Errorf("failed to scan team: %w", err)
+		}
+		teams[teamID] = json.RawMessage(data)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	data, err := json.Marshal(teams)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal teams: %w", err)
+	}
+	return data, nil
+}
+
+// ImportContest 在一个事务中保存比赛的原始数据，只有内容变化的部分才更新修订号
+func (s *Store) ImportContest(contestID string, config, teams, runs []byte) error {
+	var contest model.Contest
+	if err := json.Unmarshal(config, &contest); err != nil {
+		return fmt.Errorf("failed to parse config: %w", err)
+	}
+	var teamData map[string]json.RawMessage
+	if err := json.Unmarshal(teams, &teamData); err != nil {
+		return fmt.Errorf("failed to parse teams: %w", err)
+	}
+	var runList []*model.Run
+	if err := json.Unmarshal(runs, &runList); err != nil {
+		return fmt.Errorf("failed to parse runs: %w", err)
+	}
+
+	tx, err := s.db.Begin()
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	var old struct{ configHash, teamsHash, runsHash string }
+	err = tx.QueryRow("SELECT config_hash, teams_hash, runs_hash FROM contests WHERE id = ?", contestID).
+		Scan(&old.configHash, &old.teamsHash, &old.runsHash)
+	if err != nil && !errors.Is(err, sql.ErrNoRows) {
+		return fmt.Errorf("failed to query contest: %w", err)
+	}
+
+	configHash, teamsHash, runsHash := hash(config), hash(teams), hash(runs)
+	_, err = tx.Exec(`INSERT INTO contests (id, config, name, start_time, end_time, organization, config_hash, teams_hash, runs_hash)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?)
+		ON CONFLICT (id) DO UPDATE SET config = excluded.config, name = excluded.name,
+			start_time = excluded.start_time, end_time = excluded.end_time, organization = excluded.organization,
+			config_hash = excluded.config_hash, teams_hash = excluded.teams_hash, runs_hash = excluded.runs_hash`,
+		contestID, string(config), contest.Name, contest.StartTime, contest.EndTime, contest.Organization,
+		configHash, teamsHash, runsHash)
+	if err != nil {
+		return fmt.Errorf("failed to save contest: %w", err)
+	}
+
+	if configHash != old.configHash {
+		if err := bumpRevision(tx, contestID, "config_rev"); err != nil {
+			return err
+		}
+	}
+
+	if teamsHash != old.teamsHash {
+		if err := replaceTeams(tx, contestID, teamData); err != nil {
+			return err
+		}
+		if err := bumpRevision(tx, contestID, "teams_rev"); err != nil {
+			return err
+		}
+	}
+
+	if runsHash != old.runsHash {
+		if err := replaceRuns(tx, contestID, runList); err != nil {
+			return err
+		}
+		if err := bumpRevision(tx, contestID, "runs_rev"); err != nil {
+			return err
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit contest: %w", err)
+	}
+	return nil
+}
+
+// LoadFreezeState 读取比赛的封榜状态
+func (s *Store) LoadFreezeState(contestID string) (*model.FreezeState, error) {
+	var unfreezeTime sql.NullInt64
+	err := s.db.QueryRow("SELECT unfreeze_time FROM contests WHERE id = ?", contestID).Scan(&unfreezeTime)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, fmt.Errorf("contest %s %w", contestID, model.ErrNotExist)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to query freeze state: %w", err)
+	}
+
+	if !unfreezeTime.Valid {
+		return nil, nil
+	}
+	return &model.FreezeState{UnfreezeTime: unfreezeTime.Int64}, nil
+}
+
+// SaveFreezeState 保存比赛的封榜状态
+func (s *Store) SaveFreezeState(contestID string, state *model.FreezeState) error {
+	// 封榜状态没有变化时不更新修订号
+	result, err := s.db.Exec(`UPDATE contests SET config_rev = config_rev + (unfreeze_time IS NOT ?), unfreeze_time = ?
+		WHERE id = ?`, state.UnfreezeTime, state.UnfreezeTime, contestID)
+	if err != nil {
+		return fmt.Errorf("failed to save freeze state: %w", err)
+	}
+	if n, err := result.RowsAffected(); err == nil && n == 0 {
+		return fmt.Errorf("contest %s %w", contestID, model.ErrNotExist)
+	}
+	return nil
+}
+
+// LoadDirectory 读取比赛目录
+func (s *Store) LoadDirectory() (*model.ContestDirectory, error) {
+	var saved string
+	err := s.db.QueryRow("SELECT value FROM metadata WHERE key = ?", directorySavedKey).Scan(&saved)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, fmt.Errorf("directory %w", model.ErrNotExist)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to query directory: %w", err)
+	}
+
+	return loadDirectory(s.db)
+}
+
+// queryer 可以执行查询的数据库或事务
+type queryer interface {
+	Query(query string, args ...interface{}) (*sql.Rows, error)
+}
+
+// loadDirectory 读取比赛目录的全部比赛
+func loadDirectory(q queryer) (*model.ContestDirectory, error) {
+	rows, err := q.Query("SELECT contest_id, name, start_time, end_time, organization, type FROM directory")
+	if err != nil {
+		return nil, fmt.Errorf("failed to query directory: %w", err)
+	}
+	defer rows.Close()
+
+	directory := &model.ContestDirectory{Contests: make(map[string]model.ContestInfo)}
+	for rows.Next() {
+		var info model.ContestInfo
+		if err := rows.Scan(&info.ID, &info.Name, &info.StartTime, &info.EndTime, &info.Organization, &info.Type); err != nil {
+			return nil, fmt.Errorf("failed to scan directory: %w", err)
+		}
+		directory.Contests[info.ID] = info
+	}
+	return directory, rows.Err()
+}
+
+// UpdateDirectory 在一个写事务中读取、修改并替换比赛目录
+func (s *Store) UpdateDirectory(update func(directory *model.ContestDirectory) error) error {
+	tx, err := s.db.Begin()
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	// 先写入再读取，让事务一开始就持有写锁，并发的更新依次执行
+	if _, err := tx.Exec("INSERT OR REPLACE INTO metadata (key, value) VALUES (?, '1')", directorySavedKey); err != nil {
+		return fmt.Errorf("failed to lock directory: %w", err)
+	}
+
+	directory, err := loadDirectory(tx)
+	if err != nil {
+		return err
+	}
+	if err := update(directory); err != nil {
+		return err
+	}
+
+	if _, err := tx.Exec("DELETE FROM directory"); err != nil {
+		return fmt.Errorf("failed to clear directory: %w", err)
+	}
+	for contestID, info := range directory.Contests {
+		_, err := tx.Exec(`INSERT INTO directory (contest_id, name, start_time, end_time, organization, type)
+			VALUES (?, ?, ?, ?, ?, ?)`, contestID, info.Name, info.StartTime, info.EndTime, info.Organization, info.Type)
+		if err != nil {
+			return fmt.Errorf("failed to save directory: %w", err)
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit directory: %w", err)
+	}
+	return nil
+}
+
+// checkContest 检查比赛是否存在
+func (s *Store) checkContest(contestID string) error {
+	var exists int
+	err := s.db.QueryRow("SELECT 1 FROM contests WHERE id = ?", contestID).Scan(&exists)
+	if errors.Is(err, sql.ErrNoRows) {
+		return fmt.Errorf("contest %s %w", contestID, model.ErrNotExist)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to query contest: %w", err)
+	}
+	return nil
+}
+
+// replaceTeams 替换比赛的全部队伍
+func replaceTeams(tx *sql.Tx, contestID string, teams map[string]json.RawMessage) error {
+	if _, err := tx.Exec("DELETE FROM teams WHERE contest_id = ?", contestID); err != nil {
+		return fmt.Errorf("failed to clear teams: %w", err)
+	}
+
+	stmt, err := tx.Prepare("INSERT INTO teams (contest_id, team_id, name, organization, data) VALUES (?, ?, ?, ?, ?)")
+	if err != nil {
+		return fmt.Errorf("failed to prepare teams: %w", err)
+	}
+	defer stmt.Close()
+
+	for teamID, data := range teams {
+		var team model.Team
+		if err := json.Unmarshal(data, &team); err != nil {
+			return fmt.Errorf("failed to parse team %s: %w", teamID, err)
+		}
+		if _, err := stmt.Exec(contestID, teamID, team.Name, team.Organization, string(data)); err != nil {
+			return fmt.Errorf("failed to save team %s: %w", teamID, err)
+		}
+	}
+	return nil
+}
+
+// replaceRuns 替换比赛的全部提交记录
+func replaceRuns(tx *sql.Tx, contestID string, runs []*model.Run) error {
+	if _, err := tx.Exec("DELETE FROM runs WHERE contest_id = ?", contestID); err != nil {
+		return fmt.Errorf("failed to clear runs: %w", err)
+	}
+
+	stmt, err := tx.Prepare(`INSERT INTO runs (contest_id, seq, submission_id, team_id, problem_id, timestamp, status, language, score)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?)`)
+	if err != nil {
+		return fmt.Errorf("failed to prepare runs: %w", err)
+	}
+	defer stmt.Close()
+
+	for i, run := range runs {
+		_, err := stmt.Exec(contestID, i, run.ID, run.TeamID, run.ProblemID, run.Timestamp, run.Status, run.Language, run.Score)
+		if err != nil {
+			return fmt.Errorf("failed to save run %s: %w", run.ID, err)
+		}
+	}
+	return nil
+}
+
+// bumpRevision 增加比赛某部分数据的修订号
+func bumpRevision(tx *sql.Tx, contestID string, column string) error {
+	if _, err := tx.Exec("UPDATE contests SET "+column+" = "+column+" + 1 WHERE id = ?", contestID); err != nil {
+		return fmt.Errorf("failed to update %s: %w", column, err)
+	}
+	return nil
+}
+
+// scanRuns 读取查询到的提交记录
+func scanRuns(rows *sql.Rows) ([]*model.Run, error) {
+	var runs []*model.Run
+	for rows.Next() {
+		var run model.Run
+		if err := rows.Scan(&run.ID, &run.Status, &run.TeamID, &run.ProblemID, &run.Timestamp, &run.Language, &run.Score); err != nil {
+			return nil, fmt.Errorf("failed to scan run: %w", err)
+		}
+		runs = append(runs, &run)
+	}
+	return runs, rows.Err()
+}
+
+// hash 计算数据的摘要，用于判断导入的数据是否变化
+func hash(data []byte) string {
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,108 @@
+package sqlitestore
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/lllllan02/scoreboard/internal/model"
+	"github.com/lllllan02/scoreboard/internal/model/modeltest"
+)
+
+// openTestStore 打开内存数据库
+func openTestStore(t *testing.T) *Store {
+	t.Helper()
+
+	store, err := Open(":memory:")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { store.Close() })
+	return store
+}
+
+func TestStore(t *testing.T) {
+	modeltest.TestContestStore(t, openTestStore(t))
+}
+
+func TestQueryRuns(t *testing.T) {
+	store := openTestStore(t)
+	contest := modeltest.NewContest()
+
+	// 提交记录包括重判产生的重复编号、jury 提交和题目编号无效的提交
+	statuses := []string{"WRONG_ANSWER", "ACCEPTED", "TIME_LIMIT_EXCEEDED", "COMPILATION_ERROR"}
+	var runs []*model.Run
+	for i := 0; i < 40; i++ {
+		runs = append(runs, &model.Run{
+			ID:        fmt.Sprint(i + 1),
+			TeamID:    fmt.Sprintf("t%d", i%3+1),
+			ProblemID: i % 4, // 题目编号 3 无效
+			Timestamp: int64(i*300 + 7),
+			Status:    statuses[i%len(statuses)],
+		})
+	}
+	runs = append(runs,
+		&model.Run{ID: "5", TeamID: "t2", ProblemID: 0, Timestamp: 1207, Status: "ACCEPTED"},
+		&model.Run{ID: "12", TeamID: "t3", ProblemID: 1, Timestamp: 3307, Status: "RUNTIME_ERROR"},
+		&model.Run{ID: "41", TeamID: "jury", ProblemID: 2, Timestamp: 12345, Status: "ACCEPTED"},
+	)
+	modeltest.ImportContest(t, store, contest, modeltest.NewTeams("t1", "t2", "t3"), runs)
+
+	tests := []struct {
+		name      string
+		query     model.RunQuery
+		wantIDs   string
+		wantTotal int
+	}{
+		{
+			name:      "latest first",
+			query:     model.RunQuery{ProblemCount: 3, Limit: 5},
+			wantIDs:   "[41 39 38 37 35]",
+			wantTotal: 32,
+		},
+		{
+			name:      "offset",
+			query:     model.RunQuery{ProblemCount: 3, Offset: 29},
+			wantIDs:   "[3 2 1]",
+			wantTotal: 32,
+		},
+		{
+			name:      "team filter",
+			query:     model.RunQuery{ProblemCount: 3, TeamIDs: []string{"t2"}},
+			wantIDs:   "[38 35 29 26 23 17 14 11 5 2]",
+			wantTotal: 10,
+		},
+		{
+			// 提交 12 的原记录题目编号无效，重判后的记录有效
+			name:      "rejudged submissions keep the last record",
+			query:     model.RunQuery{ProblemCount: 3, TeamIDs: []string{"t3"}, Limit: 3, Offset: 6},
+			wantIDs:   "[15 12 9]",
+			wantTotal: 11,
+		},
+		{
+			name:      "no teams",
+			query:     model.RunQuery{ProblemCount: 3, TeamIDs: []string{}},
+			wantIDs:   "[]",
+			wantTotal: 0,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			runs, total, err := store.QueryRuns(contest.ID, tt.query)
+			if err != nil {
+				t.Fatal(err)
+			}
+			ids := make([]string, len(runs))
+			for i, run := range runs {
+				ids[i] = run.ID
+			}
+			if got := fmt.Sprint(ids); got != tt.wantIDs || total != tt.wantTotal {
+				t.Errorf("got %s (total %d), want %s (total %d)", got, total, tt.wantIDs, tt.wantTotal)
+			}
+		})
+	}
+
+	if _, _, err := store.QueryRuns("missing", model.RunQuery{ProblemCount: 3}); err == nil {
+		t.Error("QueryRuns of a missing contest returned no error")
+	}
+}
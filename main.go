@@ -1,37 +1,141 @@
+// scoreboard 记分板服务器和命令行工具
+//
+// 用法：scoreboard <command> [flags] [args]，不带命令时启动服务器
+// 所有命令共用 -config、-data、-db、-log-level 参数以及对应的配置文件和环境变量
 package main
 
 import (
-	"log"
-	"net/http"
+	"errors"
+	"flag"
+	"fmt"
+	"log/slog"
 	"os"
+	"strings"
 
-	"github.com/lllllan02/scoreboard/internal/handler"
-	"github.com/lllllan02/scoreboard/internal/service"
+	"github.com/lllllan02/scoreboard/internal/config"
+	"github.com/lllllan02/scoreboard/internal/model"
+	"github.com/lllllan02/scoreboard/internal/sqlitestore"
 )
 
+// command 子命令
+type command struct {
+	name    string
+	summary string
+	run     func(args []string) error
+}
+
+var commands = []command{
+	{"serve", "start the scoreboard server", runServe},
+	{"crawl", "download contests from board.xcpcio.com into the store", runCrawl},
+	{"validate", "check contest data for problems", runValidate},
+	{"export", "export contests from the store to a data directory", runExport},
+	{"import", "import contests from a data directory into the store", runImport},
+	{"rank", "print the scoreboard of a contest", runRank},
+	{"resolve", "print the award ceremony reveal steps of a contest", runResolve},
+	{"rebuild-directory", "rescan the store and repair the contest directory", runRebuildDirectory},
+}
+
+// errUsage 参数错误，已输出用法
+var errUsage = errors.New("usage")
+
 func main() {
-	// 初始化服务层
-	scoreSvc := service.NewScoreboardService()
-	log.Printf("Scoreboard service initialized, will load contest data on-demand")
-
-	// 设置静态文件服务
-	staticDir := http.FileServer(http.Dir("web/static"))
-	http.Handle("/static/", http.StripPrefix("/static/", staticDir))
-
-	// 注册路由处理器
-	http.HandleFunc("/", handler.IndexHandler(scoreSvc))
-	http.HandleFunc("/contest/", handler.ContestHandler(scoreSvc))
-	http.HandleFunc("/api/scoreboard/", handler.ScoreboardHandler(scoreSvc))
-	http.HandleFunc("/api/statistics/", handler.StatisticsHandler(scoreSvc))
-	http.HandleFunc("/api/submissions/", handler.SubmissionsHandler(scoreSvc))
-
-	// 启动服务器
-	port := os.Getenv("PORT")
-	if port == "" {
-		port = "8080"
-	}
-	log.Printf("Server starting on :%s", port)
-	if err := http.ListenAndServe(":"+port, nil); err != nil {
-		log.Fatalf("Server failed to start: %v", err)
+	args := os.Args[1:]
+
+	// 不带命令或直接以参数开头时启动服务器，兼容旧的启动方式
+	name := "serve"
+	if len(args) > 0 && !strings.HasPrefix(args[0], "-") {
+		name, args = args[0], args[1:]
+	}
+
+	if name == "help" {
+		usage()
+		return
+	}
+
+	for _, cmd := range commands {
+		if cmd.name != name {
+			continue
+		}
+		err := cmd.run(args)
+		switch {
+		case err == nil:
+		case errors.Is(err, flag.ErrHelp):
+		case errors.Is(err, errUsage):
+			os.Exit(2)
+		default:
+			fmt.Fprintf(os.Stderr, "scoreboard %s: %v\n", name, err)
+			os.Exit(1)
+		}
+		return
+	}
+
+	fmt.Fprintf(os.Stderr, "scoreboard: unknown command %q\n", name)
+	usage()
+	os.Exit(2)
+}
+
+// usage 输出所有命令
+func usage() {
+	fmt.Fprintln(os.Stderr, "Usage: scoreboard <command> [flags] [args]")
+	fmt.Fprintln(os.Stderr)
+	fmt.Fprintln(os.Stderr, "Commands:")
+	for _, cmd := range commands {
+		fmt.Fprintf(os.Stderr, "  %-18s %s\n", cmd.name, cmd.summary)
+	}
+	fmt.Fprintln(os.Stderr)
+	fmt.Fprintln(os.Stderr, `Run "scoreboard <command> -h" for the flags of a command.`)
+}
+
+// newFlagSet 创建子命令的参数集并注册共用的配置参数
+func newFlagSet(name, args string) (*flag.FlagSet, *config.Flags) {
+	fs := flag.NewFlagSet("scoreboard "+name, flag.ContinueOnError)
+	fs.Usage = func() {
+		fmt.Fprintf(fs.Output(), "Usage: scoreboard %s [flags] %s\n\nFlags:\n", name, args)
+		fs.PrintDefaults()
+	}
+	return fs, config.NewFlags(fs)
+}
+
+// loadConfig 加载配置并设置日志级别，标准库 log 的输出按 info 级别处理
+func loadConfig(flags *config.Flags) (*config.Config, error) {
+	cfg, err := flags.Load()
+	if err != nil {
+		return nil, err
+	}
+
+	level, _ := cfg.SlogLevel()
+	slog.SetDefault(slog.New(slog.NewTextHandler(os.Stderr, &slog.HandlerOptions{Level: level})))
+	return cfg, nil
+}
+
+// openStore 打开比赛数据存储：设置数据库时使用 SQLite，否则使用数据目录
+// 返回的函数用于关闭存储
+func openStore(cfg *config.Config) (model.ContestStore, func(), error) {
+	if cfg.DBPath == "" {
+		return model.NewFileStore(cfg.DataDir), func() {}, nil
+	}
+
+	db, err := sqlitestore.Open(cfg.DBPath)
+	if err != nil {
+		return nil, nil, err
+	}
+	return db, func() { db.Close() }, nil
+}
+
+// parseFlags 解析子命令的参数，参数错误时 flag 包已经输出了错误和用法
+func parseFlags(fs *flag.FlagSet, args []string) error {
+	err := fs.Parse(args)
+	if err != nil && !errors.Is(err, flag.ErrHelp) {
+		return errUsage
+	}
+	return err
+}
+
+// requireArgs 检查位置参数的数量，不足时输出用法
+func requireArgs(fs *flag.FlagSet, min int) error {
+	if fs.NArg() < min {
+		fs.Usage()
+		return errUsage
 	}
+	return nil
 }
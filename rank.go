@@ -0,0 +1,164 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/signal"
+	"sort"
+	"strconv"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/lllllan02/scoreboard/internal/model"
+	"github.com/lllllan02/scoreboard/internal/service"
+)
+
+// builtinFilters 不依赖比赛组别的筛选条件
+var builtinFilters = []string{"all", "official", "unofficial", "girls", "undergraduate", "special"}
+
+// runRank 在终端输出比赛的记分板，--watch 时在比赛数据变化后重新输出
+func runRank(args []string) error {
+	fs, flags := newFlagSet("rank", "<contest-id>")
+	filter := fs.String("filter", "", "filter teams: official, unofficial, girls, undergraduate, special or a group key")
+	top := fs.Int("top", 0, "only show teams ranked within the top N, 0 shows all teams")
+	at := fs.String("time", "", "show the board as of a time: unix seconds, RFC 3339, or a duration since the start such as 4h")
+	format := fs.String("format", "table", "output format: table, markdown or jsonl")
+	watch := fs.Bool("watch", false, "redraw the board whenever the contest data changes")
+	if err := parseFlags(fs, args); err != nil {
+		return err
+	}
+	if err := requireArgs(fs, 1); err != nil {
+		return err
+	}
+
+	render, ok := boardRenderers[*format]
+	if !ok {
+		return fmt.Errorf("unknown format %q, expected table, markdown or jsonl", *format)
+	}
+	if *top < 0 {
+		return fmt.Errorf("invalid top %d", *top)
+	}
+
+	cfg, err := loadConfig(flags)
+	if err != nil {
+		return err
+	}
+	store, closeStore, err := openStore(cfg)
+	if err != nil {
+		return err
+	}
+	defer closeStore()
+
+	contestID := fs.Arg(0)
+	svc := service.NewScoreboardService(store)
+	contest, err := svc.GetContest(contestID)
+	if err != nil {
+		return err
+	}
+	if err := checkFilter(contest, *filter); err != nil {
+		return err
+	}
+	asOf, err := parseAsOf(contest, *at)
+	if err != nil {
+		return err
+	}
+
+	// draw 获取最新的记分板并输出，watch 时先清屏
+	draw := func() error {
+		scoreboard, err := svc.GetScoreboardWithFilter(contestID, *filter, asOf)
+		if err != nil {
+			return err
+		}
+		if *watch && *format != "jsonl" {
+			fmt.Fprint(os.Stdout, "\x1b[H\x1b[2J")
+		}
+		return render(os.Stdout, scoreboard, topResults(scoreboard.Results, *top), asOf)
+	}
+
+	if err := draw(); err != nil || !*watch {
+		return err
+	}
+
+	// 监听比赛数据的变化，收到 SIGINT 或 SIGTERM 后退出
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	events, unsubscribe := svc.Events().Subscribe(contestID)
+	defer unsubscribe()
+	go service.NewWatcher(svc, time.Duration(cfg.WatchInterval)).Run(ctx)
+
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case event := <-events:
+			if event.Type == service.EventContestRemoved {
+				return fmt.Errorf("contest removed: %s", contestID)
+			}
+			if err := draw(); err != nil {
+				return err
+			}
+		}
+	}
+}
+
+// checkFilter 检查筛选条件是否为内置条件或比赛的组别
+func checkFilter(contest *model.Contest, filter string) error {
+	if filter == "" {
+		return nil
+	}
+	for _, name := range builtinFilters {
+		if filter == name {
+			return nil
+		}
+	}
+	if _, ok := contest.Groups[filter]; ok {
+		return nil
+	}
+
+	builtin := make(map[string]bool, len(builtinFilters))
+	for _, name := range builtinFilters {
+		builtin[name] = true
+	}
+	var groups []string
+	for group := range contest.Groups {
+		if !builtin[group] {
+			groups = append(groups, group)
+		}
+	}
+	sort.Strings(groups)
+	valid := append(append([]string(nil), builtinFilters...), groups...)
+	return fmt.Errorf("unknown filter %q, expected one of: %s", filter, strings.Join(valid, ", "))
+}
+
+// parseAsOf 解析截至时刻，支持 Unix 时间戳（秒）、RFC 3339 时间和相对比赛开始的时长，为空时返回 0
+func parseAsOf(contest *model.Contest, value string) (int64, error) {
+	if value == "" {
+		return 0, nil
+	}
+	if seconds, err := strconv.ParseInt(value, 10, 64); err == nil && seconds > 0 {
+		return seconds, nil
+	}
+	if t, err := time.Parse(time.RFC3339, value); err == nil {
+		return t.Unix(), nil
+	}
+	if d, err := time.ParseDuration(value); err == nil && d >= 0 {
+		return contest.StartTime + int64(d/time.Second), nil
+	}
+	return 0, fmt.Errorf("invalid time %q", value)
+}
+
+// topResults 只保留排名在前 n 名（含并列）的队伍，n 为 0 时保留全部
+func topResults(results []*model.Result, n int) []*model.Result {
+	if n == 0 {
+		return results
+	}
+	for i, result := range results {
+		if result.Rank > n {
+			return results[:i]
+		}
+	}
+	return results
+}
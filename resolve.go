@@ -0,0 +1,58 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+
+	"github.com/lllllan02/scoreboard/internal/service"
+)
+
+// runResolve 输出比赛颁奖仪式的揭晓步骤，每行一步
+func runResolve(args []string) error {
+	fs, flags := newFlagSet("resolve", "<contest-id>")
+	jsonLines := fs.Bool("json", false, "print one JSON object per step")
+	if err := parseFlags(fs, args); err != nil {
+		return err
+	}
+	if err := requireArgs(fs, 1); err != nil {
+		return err
+	}
+
+	cfg, err := loadConfig(flags)
+	if err != nil {
+		return err
+	}
+	store, closeStore, err := openStore(cfg)
+	if err != nil {
+		return err
+	}
+	defer closeStore()
+
+	resolver, _, err := service.NewScoreboardService(store).GetResolver(fs.Arg(0))
+	if err != nil {
+		return err
+	}
+
+	encoder := json.NewEncoder(os.Stdout)
+	for _, step := range resolver.Steps {
+		if *jsonLines {
+			if err := encoder.Encode(step); err != nil {
+				return err
+			}
+			continue
+		}
+
+		switch step.Type {
+		case service.ResolverStepReveal:
+			verdict := "rejected"
+			if step.Solved {
+				verdict = "solved"
+			}
+			fmt.Printf("%d\treveal\t%s\t%s %s\t%d -> %d\n", step.Index, step.TeamName, step.ProblemID, verdict, step.OldRank, step.NewRank)
+		case service.ResolverStepAward:
+			fmt.Printf("%d\taward\t%s\t%s\t%d\n", step.Index, step.TeamName, step.Medal, step.NewRank)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,133 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"log"
+	"net/http"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"github.com/lllllan02/scoreboard/internal/config"
+	"github.com/lllllan02/scoreboard/internal/handler"
+	"github.com/lllllan02/scoreboard/internal/service"
+	"github.com/lllllan02/scoreboard/web"
+)
+
+// runServe 启动服务器，收到 SIGINT 或 SIGTERM 后优雅退出
+func runServe(args []string) error {
+	fs, flags := newFlagSet("serve", "")
+	flags.RegisterServer()
+	if err := parseFlags(fs, args); err != nil {
+		return err
+	}
+
+	cfg, err := loadConfig(flags)
+	if err != nil {
+		return err
+	}
+	return serve(cfg)
+}
+
+// serve 按配置启动服务器
+func serve(cfg *config.Config) error {
+	store, closeStore, err := openStore(cfg)
+	if err != nil {
+		return err
+	}
+	defer closeStore()
+
+	// 页面模板和静态文件默认使用编译进程序的版本，开发模式下从磁盘加载
+	templateFS, staticFS := web.Templates(), web.Static()
+	if cfg.Dev {
+		templateFS, staticFS = os.DirFS(cfg.TemplateDir), os.DirFS(cfg.StaticDir)
+		log.Printf("Dev mode: serving templates from %s and static files from %s", cfg.TemplateDir, cfg.StaticDir)
+	}
+
+	static, err := handler.NewStaticFiles(staticFS, cfg.Dev)
+	if err != nil {
+		return err
+	}
+	if err := handler.LoadTemplates(templateFS, static, cfg.Dev); err != nil {
+		return err
+	}
+
+	// 初始化服务层
+	scoreSvc := service.NewScoreboardService(store)
+	log.Printf("Scoreboard service initialized, will load contest data on-demand")
+
+	// 后台任务在服务器开始关闭时停止
+	background, stopBackground := context.WithCancel(context.Background())
+	defer stopBackground()
+
+	// 监听比赛数据变化，重新加载受影响的比赛并发布更新事件
+	go service.NewWatcher(scoreSvc, time.Duration(cfg.WatchInterval)).Run(background)
+
+	// 把比赛更新事件转换为推送给客户端的消息
+	feed := service.NewFeed(scoreSvc)
+	go feed.Run(background)
+
+	mux := http.NewServeMux()
+
+	// 设置静态文件服务
+	mux.Handle("/static/", static)
+
+	// 注册路由处理器
+	mux.HandleFunc("/", handler.IndexHandler(scoreSvc))
+	mux.HandleFunc("/contest/", handler.ContestHandler(scoreSvc))
+	mux.HandleFunc("/api/scoreboard/", handler.ScoreboardHandler(scoreSvc))
+	mux.HandleFunc("/api/statistics/", handler.StatisticsHandler(scoreSvc))
+	mux.HandleFunc("/api/submissions/", handler.SubmissionsHandler(scoreSvc))
+	mux.HandleFunc("/api/organizations/", handler.OrganizationsHandler(scoreSvc))
+	mux.HandleFunc("/api/events/", handler.EventsHandler(feed))
+
+	// 管理接口，需要 ADMIN_TOKEN
+	mux.HandleFunc("/api/admin/unfreeze/", handler.UnfreezeHandler(scoreSvc, cfg.AdminToken))
+	mux.HandleFunc("/api/resolver/", handler.ResolverHandler(scoreSvc, cfg.AdminToken))
+
+	server := &http.Server{
+		Addr:         cfg.Addr,
+		Handler:      mux,
+		ReadTimeout:  time.Duration(cfg.ReadTimeout),
+		WriteTimeout: time.Duration(cfg.WriteTimeout),
+		IdleTimeout:  time.Duration(cfg.IdleTimeout),
+	}
+	// 开始关闭时停止推送，SSE 连接随之结束，客户端会重连到新的实例
+	server.RegisterOnShutdown(stopBackground)
+
+	// 启动服务器
+	errCh := make(chan error, 1)
+	go func() {
+		log.Printf("Server starting on %s (tls: %v)", cfg.Addr, cfg.TLSEnabled())
+		if cfg.TLSEnabled() {
+			errCh <- server.ListenAndServeTLS(cfg.TLSCert, cfg.TLSKey)
+		} else {
+			errCh <- server.ListenAndServe()
+		}
+	}()
+
+	signals, stopSignals := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stopSignals()
+
+	select {
+	case err := <-errCh:
+		return err
+	case <-signals.Done():
+	}
+
+	// 等待正在处理的请求完成
+	log.Printf("Server shutting down")
+	ctx, cancel := context.WithTimeout(context.Background(), time.Duration(cfg.ShutdownTimeout))
+	defer cancel()
+	if err := server.Shutdown(ctx); err != nil {
+		return err
+	}
+	if err := <-errCh; !errors.Is(err, http.ErrServerClosed) {
+		return err
+	}
+
+	log.Printf("Server stopped")
+	return nil
+}
@@ -0,0 +1,61 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+
+	"github.com/lllllan02/scoreboard/internal/model"
+)
+
+// runValidate 检查比赛的配置、队伍和提交记录，每个比赛输出一行 JSON 报告，不指定比赛时检查全部比赛
+// 有比赛发现错误（-strict 时包括警告）或不存在时以非零状态退出
+func runValidate(args []string) error {
+	fs, flags := newFlagSet("validate", "[contest-id...]")
+	strict := fs.Bool("strict", false, "treat warnings as errors")
+	if err := parseFlags(fs, args); err != nil {
+		return err
+	}
+
+	cfg, err := loadConfig(flags)
+	if err != nil {
+		return err
+	}
+	store, closeStore, err := openStore(cfg)
+	if err != nil {
+		return err
+	}
+	defer closeStore()
+
+	contestIDs := fs.Args()
+	if len(contestIDs) == 0 {
+		if contestIDs, err = store.ListContests(); err != nil {
+			return err
+		}
+	}
+
+	encoder := json.NewEncoder(os.Stdout)
+	failed := 0
+	for _, contestID := range contestIDs {
+		report, err := model.ValidateStore(store, contestID)
+		if err != nil {
+			// 比赛不存在时输出只包含该错误的报告
+			report = &model.ValidationReport{
+				ContestID: contestID,
+				Errors:    []model.Issue{{Type: model.IssueInvalidConfig, Message: err.Error()}},
+				Warnings:  []model.Issue{},
+			}
+		}
+		if !report.OK() || (*strict && len(report.Warnings) > 0) {
+			failed++
+		}
+		if err := encoder.Encode(report); err != nil {
+			return err
+		}
+	}
+
+	if failed > 0 {
+		return fmt.Errorf("%d of %d contests failed validation", failed, len(contestIDs))
+	}
+	return nil
+}
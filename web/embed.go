@@ -0,0 +1,25 @@
+// Package web 把页面模板和静态文件编译进程序
+package web
+
+import (
+	"embed"
+	"io/fs"
+)
+
+//go:embed templates/*.html
+var templates embed.FS
+
+//go:embed static
+var static embed.FS
+
+// Templates 编译进程序的页面模板，根目录下为 *.html
+func Templates() fs.FS {
+	sub, _ := fs.Sub(templates, "templates")
+	return sub
+}
+
+// Static 编译进程序的静态文件，根目录下为 css、js 等目录
+func Static() fs.FS {
+	sub, _ := fs.Sub(static, "static")
+	return sub
+}
@@ -0,0 +1,19 @@
+package web
+
+import (
+	"io/fs"
+	"testing"
+)
+
+func TestEmbeddedFiles(t *testing.T) {
+	for _, name := range []string{"index.html", "contest.html"} {
+		if _, err := fs.Stat(Templates(), name); err != nil {
+			t.Errorf("template %s is not embedded: %v", name, err)
+		}
+	}
+	for _, name := range []string{"css/main.css", "js/main.js", "js/scoreboard.js"} {
+		if _, err := fs.Stat(Static(), name); err != nil {
+			t.Errorf("static file %s is not embedded: %v", name, err)
+		}
+	}
+}
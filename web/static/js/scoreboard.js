@@ -85,6 +85,9 @@ document.addEventListener('DOMContentLoaded', function() {
     // 初始化进度条功能
     initProgressBar();
     
+    // 订阅记分板实时更新
+    subscribeScoreboardEvents();
+    
     console.log('初始化页面完成');
 });
 
@@ -424,6 +427,9 @@ function loadScoreboardData(selectedTimeOrFilter) {
     let selectedTime = !isFilter ? selectedTimeOrFilter : null;
     let filterType = isFilter ? selectedTimeOrFilter : currentGroup;
     
+    // 记录是否在查看历史时刻的记分板，查看历史时不自动刷新
+    window.viewingHistory = !isFilter && Number.isInteger(selectedTime);
+    
     // 有效的筛选类型列表
     const validFilters = ['all', 'official', 'unofficial', 'girls', 'undergraduate', 'special'];
     
@@ -516,6 +522,44 @@ function loadScoreboardData(selectedTimeOrFilter) {
         });
 }
 
+// 订阅记分板实时更新，排名或一血变化时重新加载记分板
+// 断线后浏览器会带着 Last-Event-ID 自动重连，服务端补发错过的消息
+function subscribeScoreboardEvents() {
+    if (typeof EventSource === 'undefined' || !window.contestInfo || !window.contestInfo.id) {
+        return;
+    }
+    
+    const source = new EventSource(`/api/events/${window.contestInfo.id}`);
+    let reloadTimer = null;
+    
+    // 同一批变化会连续推送多条消息，合并后只加载一次
+    const scheduleReload = function() {
+        if (window.viewingHistory || reloadTimer) {
+            return;
+        }
+        reloadTimer = setTimeout(function() {
+            reloadTimer = null;
+            loadScoreboardData(currentGroup || 'all');
+        }, 500);
+    };
+    
+    // 无法续传时服务端发送 sync，需要重新获取完整记分板
+    let synced = false;
+    source.addEventListener('sync', function() {
+        if (synced) {
+            scheduleReload();
+        }
+        synced = true;
+    });
+    source.addEventListener('rank', scheduleReload);
+    source.addEventListener('first_solve', scheduleReload);
+    source.addEventListener('verdict', scheduleReload);
+    
+    window.addEventListener('beforeunload', function() {
+        source.close();
+    });
+}
+
 // 渲染记分板
 function renderScoreboard(data) {
     console.log('开始渲染记分板，分离队名和学校');
@@ -570,7 +614,8 @@ function renderScoreboard(data) {
         // 排名
         const rankCell = document.createElement('td');
         rankCell.className = 'text-center';
-        rankCell.textContent = result.rank;
+        // 打星队伍不占用正式排名，显示为 *
+        rankCell.textContent = result.official_rank || '*';
         row.appendChild(rankCell);
         
         // 学校信息
@@ -679,7 +724,7 @@ function renderScoreboard(data) {
                     problemCell.classList.add('problem-failed');
                     
                     // 如果有待定提交
-                    if (problemResult.is_frozen && problemResult.pending_attempts > 0) {
+                    if (problemResult.pending_attempts > 0) {
                         problemCell.classList.add('problem-pending');
                         // 使用问号显示有冻结提交的情况
                         problemCell.innerHTML = `
@@ -692,7 +737,7 @@ function renderScoreboard(data) {
                             <div class="result-details">${problemResult.attempts}</div>
                         `;
                     }
-                } else if (problemResult.is_frozen && problemResult.pending_attempts > 0) {
+                } else if (problemResult.pending_attempts > 0) {
                     // 仅有待定提交
                     problemCell.classList.add('problem-pending');
                     problemCell.innerHTML = `
@@ -731,7 +776,7 @@ function calculateProblemStats(results, problemIds) {
                     statsMap[problemId].solvedCount++;
                 }
                 statsMap[problemId].totalAttempts += problemResult.attempts + 
-                    (problemResult.is_frozen ? problemResult.pending_attempts : 0);
+                    (problemResult.pending_attempts || 0);
             }
         });
     });
@@ -317,7 +317,7 @@ function displaySubmissions(data, filter, container) {
         const formattedTime = formatDateTime(submissionTime);
         
         // 确定结果样式
-        const statusClass = getStatusClass(submission.status);
+        const statusClass = getStatusClass(submission.verdict || submission.status);
         
         // 添加表格行
         tableHTML += `
@@ -545,6 +545,7 @@ function getStatusClass(status) {
         case 'TIME_LIMIT_EXCEEDED':
             return 'bg-warning text-dark';
         case 'MEMORY_LIMIT_EXCEEDED':
+        case 'OUTPUT_LIMIT_EXCEEDED':
             return 'bg-warning text-dark';
         case 'RUNTIME_ERROR':
             return 'bg-info';
@@ -553,6 +554,7 @@ function getStatusClass(status) {
         case 'FROZEN':
             return 'bg-primary';
         case 'PENDING':
+        case 'JUDGING':
             return 'bg-light text-dark';
         default:
             return 'bg-secondary';